@@ -0,0 +1,110 @@
+package bitfield
+
+import (
+	"sort"
+)
+
+// maxCommitteesPerSlot is the number of committees that can be tracked by committee bits.
+const maxCommitteesPerSlot = bitvector64BitSize
+
+// CommitteeAggregation holds aggregation bits of a single committee.
+type CommitteeAggregation struct {
+	CommitteeIndex  uint64
+	AggregationBits Bitlist
+}
+
+// AttestationLayout converts between per-committee aggregation bits and the attestation layout
+// that spans several committees: a Bitvector64 with a bit set for every included committee, and a
+// single Bitlist concatenating aggregation bits of the included committees in increasing committee
+// index order.
+type AttestationLayout struct {
+	committeeSizes []uint64
+}
+
+// NewAttestationLayout creates a layout for a slot with the given committee sizes, indexed by
+// committee index.
+// This method will return an error if there are more committees than committee bits.
+func NewAttestationLayout(committeeSizes []uint64) (*AttestationLayout, error) {
+	if len(committeeSizes) > maxCommitteesPerSlot {
+		return nil, ErrInvalidCommitteeIndex
+	}
+	sizes := make([]uint64, len(committeeSizes))
+	copy(sizes, committeeSizes)
+	return &AttestationLayout{
+		committeeSizes: sizes,
+	}, nil
+}
+
+// Combine builds committee bits and concatenated aggregation bits from a list of per-committee
+// aggregation bits. Committees can be listed in any order.
+// This method will return an error if a committee index is out of range or repeated, or if the
+// aggregation bits of a committee do not match its size.
+func (l *AttestationLayout) Combine(aggregations []CommitteeAggregation) (Bitvector64, Bitlist, error) {
+	sorted := make([]CommitteeAggregation, len(aggregations))
+	copy(sorted, aggregations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CommitteeIndex < sorted[j].CommitteeIndex
+	})
+
+	committeeBits := NewBitvector64()
+	var total uint64
+	for _, agg := range sorted {
+		if agg.CommitteeIndex >= uint64(len(l.committeeSizes)) {
+			return nil, nil, ErrInvalidCommitteeIndex
+		}
+		if committeeBits.BitAt(agg.CommitteeIndex) {
+			return nil, nil, ErrDuplicateCommittee
+		}
+		if agg.AggregationBits.Len() != l.committeeSizes[agg.CommitteeIndex] {
+			return nil, nil, ErrBitlistDifferentLength
+		}
+		committeeBits.SetBitAt(agg.CommitteeIndex, true)
+		total += agg.AggregationBits.Len()
+	}
+
+	aggregationBits := NewBitlist(total)
+	var offset uint64
+	for _, agg := range sorted {
+		if err := MergeAt(aggregationBits, agg.AggregationBits, offset); err != nil {
+			return nil, nil, err
+		}
+		offset += agg.AggregationBits.Len()
+	}
+	return committeeBits, aggregationBits, nil
+}
+
+// Split splits concatenated aggregation bits back into per-committee aggregation bits, ordered by
+// committee index.
+// This method will return an error if committee bits reference an unknown committee, or if the
+// length of aggregation bits does not match the total size of the committees set in committee bits.
+func (l *AttestationLayout) Split(committeeBits Bitvector64, aggregationBits Bitlist) ([]CommitteeAggregation, error) {
+	if len(committeeBits) != bitvector64ByteSize {
+		return nil, ErrWrongLen
+	}
+	indices := committeeBits.BitIndices()
+	var total uint64
+	for _, idx := range indices {
+		if idx >= len(l.committeeSizes) {
+			return nil, ErrInvalidCommitteeIndex
+		}
+		total += l.committeeSizes[idx]
+	}
+	if aggregationBits.Len() != total {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	ret := make([]CommitteeAggregation, len(indices))
+	var offset uint64
+	for i, idx := range indices {
+		bits := NewBitlist(l.committeeSizes[idx])
+		if err := ExtractAt(bits, aggregationBits, offset); err != nil {
+			return nil, err
+		}
+		ret[i] = CommitteeAggregation{
+			CommitteeIndex:  uint64(idx),
+			AggregationBits: bits,
+		}
+		offset += bits.Len()
+	}
+	return ret, nil
+}
@@ -0,0 +1,148 @@
+package bitfield
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestAttestationLayout_NewAttestationLayout(t *testing.T) {
+	if _, err := NewAttestationLayout(make([]uint64, 65)); err != ErrInvalidCommitteeIndex {
+		t.Errorf("Wrong error returned. Wanted %v, got %v", ErrInvalidCommitteeIndex, err)
+	}
+	sizes := []uint64{3, 4}
+	l, err := NewAttestationLayout(sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sizes must be copied.
+	sizes[0] = 10
+	if l.committeeSizes[0] != 3 {
+		t.Error("Layout shares committee sizes with the caller")
+	}
+}
+
+func TestAttestationLayout_Combine(t *testing.T) {
+	l, err := NewAttestationLayout([]uint64{3, 5, 4, 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	committeeBits, aggregationBits, err := l.Combine([]CommitteeAggregation{
+		{CommitteeIndex: 2, AggregationBits: Bitlist{0x19}}, // bits=[1,0,0,1]
+		{CommitteeIndex: 0, AggregationBits: Bitlist{0x0B}}, // bits=[1,1,0]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Bitvector64{0x05, 0, 0, 0, 0, 0, 0, 0}); !committeeBits.Equal(want) {
+		t.Errorf("committeeBits = %x, wanted %x", committeeBits, want)
+	}
+	// bits=[1,1,0,1,0,0,1]
+	if want := (Bitlist{0xCB}); !aggregationBits.Equal(want) {
+		t.Errorf("aggregationBits = %x, wanted %x", aggregationBits, want)
+	}
+
+	t.Run("no committees", func(t *testing.T) {
+		committeeBits, aggregationBits, err := l.Combine(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if committeeBits.Count() != 0 || aggregationBits.Len() != 0 {
+			t.Errorf("Combine(nil) = %x, %x, wanted empty", committeeBits, aggregationBits)
+		}
+	})
+
+	t.Run("check errors", func(t *testing.T) {
+		tests := []struct {
+			aggregations []CommitteeAggregation
+			err          error
+		}{
+			{
+				aggregations: []CommitteeAggregation{{CommitteeIndex: 4, AggregationBits: NewBitlist(3)}},
+				err:          ErrInvalidCommitteeIndex,
+			},
+			{
+				aggregations: []CommitteeAggregation{
+					{CommitteeIndex: 0, AggregationBits: NewBitlist(3)},
+					{CommitteeIndex: 0, AggregationBits: NewBitlist(3)},
+				},
+				err: ErrDuplicateCommittee,
+			},
+			{
+				aggregations: []CommitteeAggregation{{CommitteeIndex: 1, AggregationBits: NewBitlist(4)}},
+				err:          ErrBitlistDifferentLength,
+			},
+		}
+		for _, tt := range tests {
+			if _, _, err := l.Combine(tt.aggregations); err != tt.err {
+				t.Errorf("Wrong error returned. Wanted %v, got %v", tt.err, err)
+			}
+		}
+	})
+}
+
+func TestAttestationLayout_Split(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	sizes := make([]uint64, 64)
+	for i := range sizes {
+		sizes[i] = uint64(100 + r.Intn(100))
+	}
+	l, err := NewAttestationLayout(sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var aggregations []CommitteeAggregation
+	for _, idx := range []uint64{1, 7, 8, 30, 63} {
+		bits := NewBitlist(sizes[idx])
+		randomBits(r, bits, 50)
+		aggregations = append(aggregations, CommitteeAggregation{CommitteeIndex: idx, AggregationBits: bits})
+	}
+	committeeBits, aggregationBits, err := l.Combine(aggregations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := l.Split(committeeBits, aggregationBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, aggregations) {
+		t.Errorf("Split(Combine()) = %+v, wanted %+v", got, aggregations)
+	}
+
+	t.Run("check errors", func(t *testing.T) {
+		l, _ := NewAttestationLayout([]uint64{3, 5})
+		tests := []struct {
+			committeeBits   Bitvector64
+			aggregationBits Bitlist
+			err             error
+		}{
+			{
+				committeeBits:   Bitvector64{0x04, 0, 0, 0, 0, 0, 0, 0},
+				aggregationBits: NewBitlist(3),
+				err:             ErrInvalidCommitteeIndex,
+			},
+			{
+				committeeBits:   Bitvector64{0x03, 0, 0, 0, 0, 0, 0, 0},
+				aggregationBits: NewBitlist(7),
+				err:             ErrBitlistDifferentLength,
+			},
+			{
+				committeeBits:   Bitvector64{0x00, 0, 0, 0, 0, 0, 0, 0},
+				aggregationBits: NewBitlist(1),
+				err:             ErrBitlistDifferentLength,
+			},
+			{
+				committeeBits:   Bitvector64{0x01},
+				aggregationBits: NewBitlist(3),
+				err:             ErrWrongLen,
+			},
+		}
+		for _, tt := range tests {
+			if _, err := l.Split(tt.committeeBits, tt.aggregationBits); err != tt.err {
+				t.Errorf("Split(%x, %x) error = %v, wanted %v", tt.committeeBits, tt.aggregationBits, err, tt.err)
+			}
+		}
+	})
+}
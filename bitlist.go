@@ -1,6 +1,7 @@
 package bitfield
 
 import (
+	"math/big"
 	"math/bits"
 )
 
@@ -28,6 +29,35 @@ func NewBitlist(n uint64) Bitlist {
 	return ret
 }
 
+// NewBitlistFromIndices creates a new bitlist of size N, with bits at given indices set.
+// This method will return an error if indices are unsorted, duplicated or out of range, so that
+// converting the bitlist back with BitIndices returns the same indices.
+func NewBitlistFromIndices(n uint64, indices []int) (Bitlist, error) {
+	if err := checkIndices(n, indices); err != nil {
+		return nil, err
+	}
+	ret := NewBitlist(n)
+	for _, idx := range indices {
+		ret[idx/8] |= 1 << (idx % 8)
+	}
+	return ret, nil
+}
+
+// NewBitlistFromBools creates a new bitlist holding given values, one bit per value.
+func NewBitlistFromBools(bools []bool) Bitlist {
+	return bytesToBitlist(uint64(len(bools)), boolsToBytes(bools))
+}
+
+// NewBitlistFromBigInt creates a new bitlist of size N holding bits of a given integer, in the bit
+// order of BitAt. This method will return an error if the integer is negative or exceeds N bits.
+func NewBitlistFromBigInt(n uint64, x *big.Int) (Bitlist, error) {
+	data, err := bigIntToBytes(n, x)
+	if err != nil {
+		return nil, err
+	}
+	return bytesToBitlist(n, data), nil
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitlist, then this method returns false.
 func (b Bitlist) BitAt(idx uint64) bool {
@@ -300,3 +330,127 @@ func (b Bitlist) BitIndices() []int {
 
 	return indices
 }
+
+// Equal returns true if both bitlists have the same length and the same bits set.
+func (b Bitlist) Equal(c Bitlist) bool {
+	return equalBits(b, b.Len(), c, c.Len())
+}
+
+// Compare returns an integer comparing two bitlists lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c. See Compare for the ordering rules.
+func (b Bitlist) Compare(c Bitlist) int {
+	return compareBits(b, b.Len(), c, c.Len())
+}
+
+// Hash returns a stable 64-bit hash of the bitlist. Equal bitfields have equal hashes, regardless
+// of their type.
+func (b Bitlist) Hash() uint64 {
+	return hashBits(b, b.Len())
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitlists are not the same length.
+func (b Bitlist) HammingDistance(c Bitlist) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	return xorCountBits(b, c, b.Len()), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitlists are not the same length.
+func (b Bitlist) IntersectionSize(c Bitlist) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	and, _ := andOrCountBits(b, c, b.Len())
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitlists are not the same length.
+func (b Bitlist) UnionSize(c Bitlist) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	_, or := andOrCountBits(b, c, b.Len())
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitlists are not the same length.
+func (b Bitlist) Jaccard(c Bitlist) (float64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	return jaccard(andOrCountBits(b, c, b.Len())), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitlists are not the same length.
+func (b Bitlist) IsSubsetOf(c Bitlist) (bool, error) {
+	if b.Len() != c.Len() {
+		return false, ErrBitlistDifferentLength
+	}
+	return isSubsetBits(b, c, b.Len()), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitlists are not the same length.
+func (b Bitlist) IsDisjoint(c Bitlist) (bool, error) {
+	if b.Len() != c.Len() {
+		return false, ErrBitlistDifferentLength
+	}
+	return isDisjointBits(b, c, b.Len()), nil
+}
+
+// WeightedSum returns the sum of weights[i] over every index i set in the bitlist.
+// This method will return an error if the weights are shorter than the bitlist, or if the sum
+// overflows uint64.
+func (b Bitlist) WeightedSum(weights []uint64) (uint64, error) {
+	return weightedSumBits(b, nil, b.Len(), weights)
+}
+
+// WeightedSumAnd returns the sum of weights[i] over every index i set in both bitlists.
+// This method will return an error if the bitlists are not the same length, if the weights are
+// shorter than the bitlists, or if the sum overflows uint64.
+func (b Bitlist) WeightedSumAnd(c Bitlist, weights []uint64) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	return weightedSumBits(b, c, b.Len(), weights)
+}
+
+// HashTreeRoot returns the SSZ hash_tree_root of the bitlist, with a given limit of bits.
+// This method will return an error if the bitlist is longer than the limit.
+func (b Bitlist) HashTreeRoot(limit uint64) ([32]byte, error) {
+	b64, err := b.ToBitlist64()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return b64.HashTreeRoot(limit)
+}
+
+// Apply flips all bits of a given patch in the bitlist.
+// This method will return an error if the patch was built for a different length.
+func (b Bitlist) Apply(p *Patch) error {
+	return p.Apply(b)
+}
+
+// ToBools returns bits of the bitlist as an array of booleans, without the length bit.
+func (b Bitlist) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitlist as a non-negative integer, in the bit order of BitAt and
+// without the length bit.
+func (b Bitlist) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
+// Words returns bits of the bitlist as an array of words without the length bit, in the layout
+// used by Bitlist64.
+func (b Bitlist) Words() []uint64 {
+	return bitfieldWords(b)
+}
@@ -3,6 +3,7 @@ package bitfield
 import (
 	"encoding/binary"
 	"fmt"
+	"math/big"
 	"math/bits"
 )
 
@@ -68,6 +69,37 @@ func NewBitlist64FromBytes(n uint64, b []byte) (*Bitlist64, error) {
 	}, nil
 }
 
+// NewBitlist64FromIndices creates a new bitlist of size `n`, with bits at given indices set.
+// This method will return an error if indices are unsorted, duplicated or out of range, so that
+// converting the bitlist back with BitIndices returns the same indices.
+func NewBitlist64FromIndices(n uint64, indices []int) (*Bitlist64, error) {
+	if err := checkIndices(n, indices); err != nil {
+		return nil, err
+	}
+	b := NewBitlist64(n)
+	for _, idx := range indices {
+		b.data[idx>>wordSizeLog2] |= 1 << (uint64(idx) % wordSize)
+	}
+	return b, nil
+}
+
+// NewBitlist64FromBools creates a new bitlist holding given values, one bit per value.
+func NewBitlist64FromBools(bools []bool) *Bitlist64 {
+	b, _ := NewBitlist64FromBytes(uint64(len(bools)), boolsToBytes(bools))
+	return b
+}
+
+// NewBitlist64FromBigInt creates a new bitlist of size `n` holding bits of a given integer, in the
+// bit order of BitAt. This method will return an error if the integer is negative or exceeds `n`
+// bits.
+func NewBitlist64FromBigInt(n uint64, x *big.Int) (*Bitlist64, error) {
+	data, err := bigIntToBytes(n, x)
+	if err != nil {
+		return nil, err
+	}
+	return NewBitlist64FromBytes(n, data)
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitlist, then this method returns false.
 func (b *Bitlist64) BitAt(idx uint64) bool {
@@ -164,12 +196,7 @@ func (b *Bitlist64) ToBitlist() Bitlist {
 
 // Count returns the number of 1s in the bitlist.
 func (b *Bitlist64) Count() uint64 {
-	c := 0
-	for _, bt := range b.data {
-		c += bits.OnesCount64(bt)
-	}
-
-	return uint64(c)
+	return popcountWords(b.data)
 }
 
 // Contains returns true if the bitlist contains all of the bits from the provided argument
@@ -237,12 +264,25 @@ func (b *Bitlist64) NoAllocOr(c, ret *Bitlist64) error {
 		return ErrBitlistDifferentLength
 	}
 
-	for idx, word := range b.data {
-		ret.data[idx] = word | c.data[idx]
-	}
+	orWords(ret.data, b.data, c.data)
 	return nil
 }
 
+// OrPooled returns the OR result of the two bitfields (union), taking the result from a given pool
+// instead of allocating it. It may be returned to the pool once no longer used.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) OrPooled(c *Bitlist64, pool *Bitlist64Pool) (*Bitlist64, error) {
+	if b.Len() != c.Len() {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	// All words are overwritten, so the result doesn't need to be zeroed.
+	ret := pool.get(b.size)
+	orWords(ret.data, b.data, c.data)
+
+	return ret, nil
+}
+
 // OrCount calculates number of bits set in a union of two bitfields.
 // This method will return an error if the bitlists are not the same length.
 func (b *Bitlist64) OrCount(c *Bitlist64) (uint64, error) {
@@ -250,12 +290,7 @@ func (b *Bitlist64) OrCount(c *Bitlist64) (uint64, error) {
 		return 0, ErrBitlistDifferentLength
 	}
 
-	var cnt int
-	for idx := range b.data {
-		cnt += bits.OnesCount64(b.data[idx] | c.data[idx])
-	}
-
-	return uint64(cnt), nil
+	return popcountOrWords(b.data, c.data), nil
 }
 
 // And returns the AND result of the two bitfields (intersection).
@@ -278,12 +313,7 @@ func (b *Bitlist64) AndCount(c *Bitlist64) (uint64, error) {
 		return 0, ErrBitlistDifferentLength
 	}
 
-	var cnt int
-	for idx := range b.data {
-		cnt += bits.OnesCount64(b.data[idx] & c.data[idx])
-	}
-
-	return uint64(cnt), nil
+	return popcountAndWords(b.data, c.data), nil
 }
 
 // NoAllocAnd computes the AND result of the two bitfields (intersection).
@@ -294,12 +324,25 @@ func (b *Bitlist64) NoAllocAnd(c, ret *Bitlist64) error {
 		return ErrBitlistDifferentLength
 	}
 
-	for idx, word := range b.data {
-		ret.data[idx] = word & c.data[idx]
-	}
+	andWords(ret.data, b.data, c.data)
 	return nil
 }
 
+// AndPooled returns the AND result of the two bitfields (intersection), taking the result from a
+// given pool instead of allocating it. It may be returned to the pool once no longer used.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) AndPooled(c *Bitlist64, pool *Bitlist64Pool) (*Bitlist64, error) {
+	if b.Len() != c.Len() {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	// All words are overwritten, so the result doesn't need to be zeroed.
+	ret := pool.get(b.size)
+	andWords(ret.data, b.data, c.data)
+
+	return ret, nil
+}
+
 // Xor returns the XOR result of the two bitfields (symmetric difference).
 // This method will return an error if the bitlists are not the same length.
 func (b *Bitlist64) Xor(c *Bitlist64) (*Bitlist64, error) {
@@ -321,12 +364,25 @@ func (b *Bitlist64) NoAllocXor(c, ret *Bitlist64) error {
 		return ErrBitlistDifferentLength
 	}
 
-	for idx, word := range b.data {
-		ret.data[idx] = word ^ c.data[idx]
-	}
+	xorWords(ret.data, b.data, c.data)
 	return nil
 }
 
+// XorPooled returns the XOR result of the two bitfields (symmetric difference), taking the result
+// from a given pool instead of allocating it. It may be returned to the pool once no longer used.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) XorPooled(c *Bitlist64, pool *Bitlist64Pool) (*Bitlist64, error) {
+	if b.Len() != c.Len() {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	// All words are overwritten, so the result doesn't need to be zeroed.
+	ret := pool.get(b.size)
+	xorWords(ret.data, b.data, c.data)
+
+	return ret, nil
+}
+
 // XorCount calculates number of bits set in a symmetric difference of two bitfields.
 // This method will return an error if the bitlists are not the same length.
 func (b *Bitlist64) XorCount(c *Bitlist64) (uint64, error) {
@@ -334,12 +390,7 @@ func (b *Bitlist64) XorCount(c *Bitlist64) (uint64, error) {
 		return 0, ErrBitlistDifferentLength
 	}
 
-	var cnt int
-	for idx := range b.data {
-		cnt += bits.OnesCount64(b.data[idx] ^ c.data[idx])
-	}
-
-	return uint64(cnt), nil
+	return popcountXorWords(b.data, c.data), nil
 }
 
 // Not returns the NOT result of the bitfield (complement).
@@ -367,6 +418,19 @@ func (b *Bitlist64) NoAllocNot(ret *Bitlist64) {
 	ret.clearUnusedBits()
 }
 
+// NotPooled returns the NOT result of the bitfield (complement), taking the result from a given
+// pool instead of allocating it. It may be returned to the pool once no longer used.
+func (b *Bitlist64) NotPooled(pool *Bitlist64Pool) *Bitlist64 {
+	// All words are overwritten, so the result doesn't need to be zeroed.
+	ret := pool.get(b.size)
+	for idx, word := range b.data[:len(ret.data)] {
+		ret.data[idx] = ^word
+	}
+	ret.clearUnusedBits()
+
+	return ret
+}
+
 // BitIndices returns list of bit indexes of bitlist where value is set to true.
 func (b *Bitlist64) BitIndices() []int {
 	indices := make([]int, b.Count())
@@ -411,6 +475,126 @@ func (b *Bitlist64) NoAllocBitIndices(ret []int) {
 	}
 }
 
+// Equal returns true if both bitlists have the same length and the same bits set.
+func (b *Bitlist64) Equal(c *Bitlist64) bool {
+	return b.size == c.size && compareWords(b.data, b.size, c.data, c.size) == 0
+}
+
+// Compare returns an integer comparing two bitlists lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c. See Compare for the ordering rules.
+func (b *Bitlist64) Compare(c *Bitlist64) int {
+	return compareWords(b.data, b.size, c.data, c.size)
+}
+
+// Hash returns a stable 64-bit hash of the bitlist. Equal bitfields have equal hashes, regardless
+// of their type.
+func (b *Bitlist64) Hash() uint64 {
+	return hashWords(b.data, b.size)
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) HammingDistance(c *Bitlist64) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	return xorCountWords(b.data, c.data, b.size), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) IntersectionSize(c *Bitlist64) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	and, _ := andOrCountWords(b.data, c.data, b.size)
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) UnionSize(c *Bitlist64) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	_, or := andOrCountWords(b.data, c.data, b.size)
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) Jaccard(c *Bitlist64) (float64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	return jaccard(andOrCountWords(b.data, c.data, b.size)), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) IsSubsetOf(c *Bitlist64) (bool, error) {
+	if b.Len() != c.Len() {
+		return false, ErrBitlistDifferentLength
+	}
+	return isSubsetWords(b.data, c.data, b.size), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitlists are not the same length.
+func (b *Bitlist64) IsDisjoint(c *Bitlist64) (bool, error) {
+	if b.Len() != c.Len() {
+		return false, ErrBitlistDifferentLength
+	}
+	return isDisjointWords(b.data, c.data, b.size), nil
+}
+
+// WeightedSum returns the sum of weights[i] over every index i set in the bitlist.
+// This method will return an error if the weights are shorter than the bitlist, or if the sum
+// overflows uint64.
+func (b *Bitlist64) WeightedSum(weights []uint64) (uint64, error) {
+	return weightedSumWords(b.data, nil, b.size, weights)
+}
+
+// WeightedSumAnd returns the sum of weights[i] over every index i set in both bitlists.
+// This method will return an error if the bitlists are not the same length, if the weights are
+// shorter than the bitlists, or if the sum overflows uint64.
+func (b *Bitlist64) WeightedSumAnd(c *Bitlist64, weights []uint64) (uint64, error) {
+	if b.Len() != c.Len() {
+		return 0, ErrBitlistDifferentLength
+	}
+	return weightedSumWords(b.data, c.data, b.size, weights)
+}
+
+// HashTreeRoot returns the SSZ hash_tree_root of the bitlist, as a Bitlist with a given limit of
+// bits. The whole tree is hashed on every call, see MerkleizedBitlist for incremental hashing.
+// This method will return an error if the bitlist is longer than the limit.
+func (b *Bitlist64) HashTreeRoot(limit uint64) ([32]byte, error) {
+	data := b.data
+	if n := numWordsRequired(b.size); n > 0 && data[n-1]&^lastWordMask(b.size) != 0 {
+		// Do not hash bits above the size.
+		data = append([]uint64(nil), data[:n]...)
+		data[n-1] &= lastWordMask(b.size)
+	}
+	return bitlistRoot(data, b.size, limit)
+}
+
+// Apply flips all bits of a given patch in the bitlist.
+// This method will return an error if the patch was built for a different length.
+func (b *Bitlist64) Apply(p *Patch) error {
+	return p.Apply(b)
+}
+
+// ToBools returns bits of the bitlist as an array of booleans.
+func (b *Bitlist64) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitlist as a non-negative integer, in the bit order of BitAt.
+func (b *Bitlist64) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
 // Clone safely copies a given bitlist.
 func (b *Bitlist64) Clone() *Bitlist64 {
 	c := NewBitlist64(b.size)
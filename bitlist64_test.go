@@ -305,6 +305,69 @@ func TestBitlist64_NewBitlist64FromBytes(t *testing.T) {
 	}
 }
 
+func TestBitlist64_NewBitlist64FromIndices(t *testing.T) {
+	tests := []struct {
+		size    uint64
+		indices []int
+		want    *Bitlist64
+		wantErr error
+	}{
+		{
+			size:    0,
+			indices: []int{},
+			want:    &Bitlist64{size: 0, data: []uint64{}},
+		},
+		{
+			size:    64,
+			indices: []int{0, 5, 63},
+			want:    &Bitlist64{size: 64, data: []uint64{0x8000000000000021}},
+		},
+		{
+			size:    130,
+			indices: []int{1, 64, 129},
+			want:    &Bitlist64{size: 130, data: []uint64{0x02, 0x01, 0x02}},
+		},
+		{
+			size:    64,
+			indices: []int{64},
+			wantErr: ErrInvalidIndices,
+		},
+		{
+			size:    64,
+			indices: []int{-1},
+			wantErr: ErrInvalidIndices,
+		},
+		{
+			size:    64,
+			indices: []int{5, 3},
+			wantErr: ErrInvalidIndices,
+		},
+		{
+			size:    64,
+			indices: []int{3, 3},
+			wantErr: ErrInvalidIndices,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("NewBitlist64FromIndices(%d, %v)", tt.size, tt.indices), func(t *testing.T) {
+			got, err := NewBitlist64FromIndices(tt.size, tt.indices)
+			if err != tt.wantErr {
+				t.Fatalf("NewBitlist64FromIndices(%d, %v) error = %v, wanted %v", tt.size, tt.indices, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewBitlist64FromIndices(%d, %v) = %+v, wanted %+v", tt.size, tt.indices, got, tt.want)
+			}
+			if len(tt.indices) > 0 && !reflect.DeepEqual(got.BitIndices(), tt.indices) {
+				t.Errorf("BitIndices() = %v, wanted %v", got.BitIndices(), tt.indices)
+			}
+		})
+	}
+}
+
 func TestBitlist64_ToBitlist(t *testing.T) {
 	tests := []struct {
 		size            uint64
@@ -2,6 +2,7 @@ package bitfield
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 )
 
@@ -821,3 +822,161 @@ func BenchmarkBitlist_BitIndices(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkBitlist_WeightedSum(b *testing.B) {
+	for n := uint64(0); n <= 2048; n += 512 {
+		weights := make([]uint64, n)
+		for i := range weights {
+			weights[i] = 32_000_000_000
+		}
+		b.Run(fmt.Sprintf("size:%d", n), func(b *testing.B) {
+			b.Run("[]byte", func(b *testing.B) {
+				b.StopTimer()
+				s := NewBitlist(n)
+				for i := uint64(0); i < n; i += 10 {
+					s.SetBitAt(i, true)
+				}
+				b.StartTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := s.WeightedSum(weights); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("[]uint64", func(b *testing.B) {
+				b.StopTimer()
+				s := NewBitlist64(n)
+				for i := uint64(0); i < n; i += 10 {
+					s.SetBitAt(i, true)
+				}
+				b.StartTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := s.WeightedSum(weights); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("[]uint64 (BitIndices)", func(b *testing.B) {
+				b.StopTimer()
+				s := NewBitlist64(n)
+				for i := uint64(0); i < n; i += 10 {
+					s.SetBitAt(i, true)
+				}
+				b.StartTimer()
+				for i := 0; i < b.N; i++ {
+					var sum uint64
+					for _, idx := range s.BitIndices() {
+						sum += weights[idx]
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkExpr(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	const n = 1 << 20
+	source, target, slashed, head := NewBitlist64(n), NewBitlist64(n), NewBitlist64(n), NewBitlist64(n)
+	for _, bl := range []*Bitlist64{source, target, slashed, head} {
+		randomBits(r, bl, 50)
+	}
+
+	b.Run("Eval", func(b *testing.B) {
+		e, err := CompileExpr("(source & target & ~slashed) | head", map[string]*Bitlist64{
+			"source": source, "target": target, "slashed": slashed, "head": head,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		dst := NewBitlist64(n)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = e.Eval(dst)
+		}
+	})
+
+	b.Run("chained", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			st, _ := source.And(target)
+			sts, _ := st.And(slashed.Not())
+			_, _ = sts.Or(head)
+		}
+	})
+}
+
+func BenchmarkWords(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []int{1 << 10, 1 << 16, 1 << 20} {
+		x, y := randomWords(r, n, 50), randomWords(r, n, 50)
+		dst := make([]uint64, n)
+		b.Run(fmt.Sprintf("words:%d", n), func(b *testing.B) {
+			b.Run("popcount", func(b *testing.B) {
+				b.SetBytes(int64(n) * bytesInWord)
+				for i := 0; i < b.N; i++ {
+					popcountWords(x)
+				}
+			})
+			b.Run("popcount (generic)", func(b *testing.B) {
+				b.SetBytes(int64(n) * bytesInWord)
+				for i := 0; i < b.N; i++ {
+					popcountWordsGeneric(x)
+				}
+			})
+			b.Run("and popcount", func(b *testing.B) {
+				b.SetBytes(int64(n) * bytesInWord)
+				for i := 0; i < b.N; i++ {
+					popcountAndWords(x, y)
+				}
+			})
+			b.Run("and popcount (generic)", func(b *testing.B) {
+				b.SetBytes(int64(n) * bytesInWord)
+				for i := 0; i < b.N; i++ {
+					popcountAndWordsGeneric(x, y)
+				}
+			})
+			b.Run("or", func(b *testing.B) {
+				b.SetBytes(int64(n) * bytesInWord)
+				for i := 0; i < b.N; i++ {
+					orWords(dst, x, y)
+				}
+			})
+			b.Run("or (generic)", func(b *testing.B) {
+				b.SetBytes(int64(n) * bytesInWord)
+				for i := 0; i < b.N; i++ {
+					orWordsGeneric(dst, x, y)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkBitlist64_Pooled(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{2048, 1 << 20} {
+		x, y := NewBitlist64(n), NewBitlist64(n)
+		randomBits(r, x, 50)
+		randomBits(r, y, 50)
+		b.Run(fmt.Sprintf("size:%d", n), func(b *testing.B) {
+			b.Run("Or", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					ret, _ := x.Or(y)
+					_, _ = ret.And(y)
+				}
+			})
+			b.Run("OrPooled", func(b *testing.B) {
+				pool := &Bitlist64Pool{}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					ret, _ := x.OrPooled(y, pool)
+					ret2, _ := ret.AndPooled(y, pool)
+					pool.Put(ret)
+					pool.Put(ret2)
+				}
+			})
+		})
+	}
+}
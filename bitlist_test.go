@@ -51,6 +51,63 @@ func TestNewBitlist(t *testing.T) {
 	}
 }
 
+func TestNewBitlistFromIndices(t *testing.T) {
+	tests := []struct {
+		size    uint64
+		indices []int
+		want    Bitlist
+		wantErr error
+	}{
+		{
+			size:    0,
+			indices: []int{},
+			want:    Bitlist{0x01},
+		},
+		{
+			size:    3,
+			indices: []int{0, 2},
+			want:    Bitlist{0x0D},
+		},
+		{
+			size:    9,
+			indices: []int{1, 8},
+			want:    Bitlist{0x02, 0x03},
+		},
+		{
+			size:    9,
+			indices: []int{9},
+			wantErr: ErrInvalidIndices,
+		},
+		{
+			size:    9,
+			indices: []int{2, 1},
+			wantErr: ErrInvalidIndices,
+		},
+		{
+			size:    9,
+			indices: []int{1, 1},
+			wantErr: ErrInvalidIndices,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitlistFromIndices(tt.size, tt.indices)
+		if err != tt.wantErr {
+			t.Errorf("NewBitlistFromIndices(%d, %v) error = %v, wanted %v", tt.size, tt.indices, err, tt.wantErr)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf(
+				"NewBitlistFromIndices(%d, %v) = %x, wanted %x",
+				tt.size,
+				tt.indices,
+				got,
+				tt.want,
+			)
+		}
+	}
+}
+
 func TestBitlist_Len(t *testing.T) {
 	tests := []struct {
 		bitlist Bitlist
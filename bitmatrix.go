@@ -0,0 +1,171 @@
+package bitfield
+
+import (
+	"math/bits"
+)
+
+// BitMatrix is a two dimensional bitfield of rows x cols bits, backed by a single array of uint64.
+// Every row is word aligned and laid out exactly like a Bitlist64 of size cols, so rows can be
+// accessed as bitlists without copying e.g. one row per slot and one column per validator.
+type BitMatrix struct {
+	rows   uint64
+	cols   uint64
+	stride int // Number of words in a single row.
+	data   []uint64
+}
+
+// NewBitMatrix creates a new bit matrix with a given number of rows and columns.
+func NewBitMatrix(rows, cols uint64) *BitMatrix {
+	stride := numWordsRequired(cols)
+	return &BitMatrix{
+		rows:   rows,
+		cols:   cols,
+		stride: stride,
+		data:   make([]uint64, int(rows)*stride),
+	}
+}
+
+// NewBitMatrixFromRows creates a new bit matrix, copying bits from a given list of bitlists.
+// This method will return an error if the bitlists are not the same length.
+func NewBitMatrixFromRows(rows []*Bitlist64) (*BitMatrix, error) {
+	if len(rows) == 0 {
+		return NewBitMatrix(0, 0), nil
+	}
+	m := NewBitMatrix(uint64(len(rows)), rows[0].Len())
+	for i, row := range rows {
+		if row.Len() != m.cols {
+			return nil, ErrBitlistDifferentLength
+		}
+		dst := m.Row(uint64(i))
+		copy(dst.data, row.data)
+		dst.clearUnusedBits()
+	}
+	return m, nil
+}
+
+// Rows returns the number of rows in the matrix.
+func (m *BitMatrix) Rows() uint64 {
+	return m.rows
+}
+
+// Cols returns the number of columns in the matrix.
+func (m *BitMatrix) Cols() uint64 {
+	return m.cols
+}
+
+// BitAt returns the bit value at the given row and column. If the position requested exceeds
+// the matrix dimensions, then this method returns false.
+func (m *BitMatrix) BitAt(row, col uint64) bool {
+	if row >= m.rows || col >= m.cols {
+		return false
+	}
+	return m.data[int(row)*m.stride+int(col>>wordSizeLog2)]&(1<<(col%wordSize)) != 0
+}
+
+// SetBitAt will set the bit at the given row and column to the given value. If the position
+// requested exceeds the matrix dimensions, then this method does nothing.
+func (m *BitMatrix) SetBitAt(row, col uint64, val bool) {
+	if row >= m.rows || col >= m.cols {
+		return
+	}
+	idx := int(row)*m.stride + int(col>>wordSizeLog2)
+	bit := uint64(1 << (col % wordSize))
+	if val {
+		m.data[idx] |= bit
+	} else {
+		m.data[idx] &^= bit
+	}
+}
+
+// Row returns the row at the given index as a bitlist of size Cols(). The bitlist is a view into
+// the matrix, so modifying it modifies the matrix. If the index requested exceeds the number of
+// rows, then this method returns nil.
+func (m *BitMatrix) Row(i uint64) *Bitlist64 {
+	if i >= m.rows {
+		return nil
+	}
+	start := int(i) * m.stride
+	return &Bitlist64{
+		size: m.cols,
+		data: m.data[start : start+m.stride : start+m.stride],
+	}
+}
+
+// Column returns a copy of the column at the given index as a bitlist of size Rows(). If the
+// index requested exceeds the number of columns, then this method returns nil.
+func (m *BitMatrix) Column(j uint64) *Bitlist64 {
+	if j >= m.cols {
+		return nil
+	}
+	ret := NewBitlist64(m.rows)
+	offset := int(j >> wordSizeLog2)
+	bit := uint64(1 << (j % wordSize))
+	for i := uint64(0); i < m.rows; i++ {
+		if m.data[int(i)*m.stride+offset]&bit != 0 {
+			ret.data[i>>wordSizeLog2] |= 1 << (i % wordSize)
+		}
+	}
+	return ret
+}
+
+// Transpose returns a new cols x rows matrix, with rows and columns of the matrix swapped.
+func (m *BitMatrix) Transpose() *BitMatrix {
+	ret := NewBitMatrix(m.cols, m.rows)
+	for i := uint64(0); i < m.rows; i++ {
+		rowBit := uint64(1 << (i % wordSize))
+		rowWord := int(i >> wordSizeLog2)
+		for idx, word := range m.data[int(i)*m.stride : int(i+1)*m.stride] {
+			for word != 0 {
+				j := idx<<wordSizeLog2 + bits.TrailingZeros64(word)
+				ret.data[j*ret.stride+rowWord] |= rowBit
+				word &= word - 1
+			}
+		}
+	}
+	return ret
+}
+
+// RowCounts returns the number of 1s in every row of the matrix.
+func (m *BitMatrix) RowCounts() []uint64 {
+	counts := make([]uint64, m.rows)
+	for i := range counts {
+		counts[i] = m.Row(uint64(i)).Count()
+	}
+	return counts
+}
+
+// ColumnCounts returns the number of 1s in every column of the matrix.
+func (m *BitMatrix) ColumnCounts() []uint64 {
+	counts := make([]uint64, m.cols)
+	for i := uint64(0); i < m.rows; i++ {
+		for idx, word := range m.data[int(i)*m.stride : int(i+1)*m.stride] {
+			for word != 0 {
+				counts[idx<<wordSizeLog2+bits.TrailingZeros64(word)]++
+				word &= word - 1
+			}
+		}
+	}
+	return counts
+}
+
+// OrRows returns the OR result of all rows i.e. the columns having at least a single bit set.
+func (m *BitMatrix) OrRows() *Bitlist64 {
+	ret := NewBitlist64(m.cols)
+	for i := uint64(0); i < m.rows; i++ {
+		// Lengths match by construction, so no error is possible.
+		_ = ret.NoAllocOr(m.Row(i), ret)
+	}
+	return ret
+}
+
+// AndRows returns the AND result of all rows i.e. the columns having all bits set. If the matrix
+// has no rows, all bits of the result are set.
+func (m *BitMatrix) AndRows() *Bitlist64 {
+	ret := NewBitlist64(m.cols)
+	ret.NoAllocNot(ret)
+	for i := uint64(0); i < m.rows; i++ {
+		// Lengths match by construction, so no error is possible.
+		_ = ret.NoAllocAnd(m.Row(i), ret)
+	}
+	return ret
+}
@@ -0,0 +1,232 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func randomBitMatrix(r *rand.Rand, rows, cols uint64) *BitMatrix {
+	m := NewBitMatrix(rows, cols)
+	for i := uint64(0); i < rows; i++ {
+		for j := uint64(0); j < cols; j++ {
+			m.SetBitAt(i, j, r.Intn(2) == 1)
+		}
+	}
+	return m
+}
+
+func TestBitMatrix_NewBitMatrix(t *testing.T) {
+	tests := []struct {
+		rows, cols uint64
+		want       *BitMatrix
+	}{
+		{
+			rows: 0,
+			cols: 0,
+			want: &BitMatrix{rows: 0, cols: 0, stride: 0, data: []uint64{}},
+		},
+		{
+			rows: 3,
+			cols: 0,
+			want: &BitMatrix{rows: 3, cols: 0, stride: 0, data: []uint64{}},
+		},
+		{
+			rows: 2,
+			cols: 64,
+			want: &BitMatrix{rows: 2, cols: 64, stride: 1, data: []uint64{0x00, 0x00}},
+		},
+		{
+			rows: 2,
+			cols: 65,
+			want: &BitMatrix{rows: 2, cols: 65, stride: 2, data: []uint64{0x00, 0x00, 0x00, 0x00}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := NewBitMatrix(tt.rows, tt.cols); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NewBitMatrix(%d, %d) = %+v, wanted %+v", tt.rows, tt.cols, got, tt.want)
+		}
+	}
+}
+
+func TestBitMatrix_NewBitMatrixFromRows(t *testing.T) {
+	rows := []*Bitlist64{
+		NewBitlist64(70),
+		NewBitlist64(70),
+	}
+	rows[0].SetBitAt(1, true)
+	rows[1].SetBitAt(69, true)
+	m, err := NewBitMatrixFromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Rows() != 2 || m.Cols() != 70 {
+		t.Errorf("Dimensions = %dx%d, wanted 2x70", m.Rows(), m.Cols())
+	}
+	for i, row := range rows {
+		if !m.Row(uint64(i)).Equal(row) {
+			t.Errorf("Row(%d) = %+v, wanted %+v", i, m.Row(uint64(i)), row)
+		}
+	}
+
+	// Rows must be copied.
+	rows[0].SetBitAt(2, true)
+	if m.BitAt(0, 2) {
+		t.Error("Matrix shares memory with source rows")
+	}
+
+	if _, err := NewBitMatrixFromRows([]*Bitlist64{NewBitlist64(1), NewBitlist64(2)}); err != ErrBitlistDifferentLength {
+		t.Errorf("Wrong error returned. Wanted %v, got %v", ErrBitlistDifferentLength, err)
+	}
+}
+
+func TestBitMatrix_SetBitAt(t *testing.T) {
+	m := NewBitMatrix(3, 100)
+	m.SetBitAt(1, 99, true)
+	m.SetBitAt(2, 0, true)
+	m.SetBitAt(3, 0, true)   // Out of bounds.
+	m.SetBitAt(0, 100, true) // Out of bounds.
+
+	want := []uint64{0x00, 0x00, 0x00, 1 << 35, 0x01, 0x00}
+	if !reflect.DeepEqual(m.data, want) {
+		t.Errorf("data = %x, wanted %x", m.data, want)
+	}
+	if !m.BitAt(1, 99) || !m.BitAt(2, 0) || m.BitAt(0, 0) || m.BitAt(3, 0) || m.BitAt(0, 100) {
+		t.Error("Unexpected BitAt() results")
+	}
+
+	m.SetBitAt(1, 99, false)
+	if m.BitAt(1, 99) {
+		t.Error("Bit was not cleared")
+	}
+}
+
+func TestBitMatrix_Row(t *testing.T) {
+	m := NewBitMatrix(3, 70)
+	row := m.Row(1)
+	if row.Len() != 70 {
+		t.Errorf("Row(1).Len() = %d, wanted 70", row.Len())
+	}
+
+	// Row is a view, changes propagate both ways.
+	row.SetBitAt(69, true)
+	if !m.BitAt(1, 69) {
+		t.Error("Row change is not visible in matrix")
+	}
+	m.SetBitAt(1, 3, true)
+	if !row.BitAt(3) {
+		t.Error("Matrix change is not visible in row")
+	}
+
+	// Operations on a row must not spill over to neighbouring rows.
+	row.NoAllocNot(row)
+	if m.Row(0).Count() != 0 || m.Row(2).Count() != 0 {
+		t.Error("Row operation modified other rows")
+	}
+	if row.Count() != 68 {
+		t.Errorf("Row(1).Count() = %d, wanted 68", row.Count())
+	}
+
+	if m.Row(3) != nil {
+		t.Error("Row(3) is expected to be nil")
+	}
+}
+
+func TestBitMatrix_Column(t *testing.T) {
+	m := NewBitMatrix(70, 3)
+	m.SetBitAt(0, 1, true)
+	m.SetBitAt(69, 1, true)
+	m.SetBitAt(5, 2, true)
+
+	want := NewBitlist64(70)
+	want.SetBitAt(0, true)
+	want.SetBitAt(69, true)
+	if got := m.Column(1); !got.Equal(want) {
+		t.Errorf("Column(1) = %+v, wanted %+v", got, want)
+	}
+	if got := m.Column(0); got.Count() != 0 || got.Len() != 70 {
+		t.Errorf("Column(0) = %+v, wanted empty", got)
+	}
+	if m.Column(3) != nil {
+		t.Error("Column(3) is expected to be nil")
+	}
+}
+
+func TestBitMatrix_Transpose(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, dims := range [][2]uint64{{0, 0}, {1, 1}, {3, 70}, {64, 64}, {65, 129}, {200, 7}} {
+		t.Run(fmt.Sprintf("%dx%d", dims[0], dims[1]), func(t *testing.T) {
+			m := randomBitMatrix(r, dims[0], dims[1])
+			tr := m.Transpose()
+			if tr.Rows() != m.Cols() || tr.Cols() != m.Rows() {
+				t.Fatalf("Transpose() dimensions = %dx%d, wanted %dx%d", tr.Rows(), tr.Cols(), m.Cols(), m.Rows())
+			}
+			for i := uint64(0); i < m.Rows(); i++ {
+				for j := uint64(0); j < m.Cols(); j++ {
+					if m.BitAt(i, j) != tr.BitAt(j, i) {
+						t.Fatalf("Transpose() bit (%d, %d) mismatch", j, i)
+					}
+				}
+			}
+			for j := uint64(0); j < m.Cols(); j++ {
+				if !m.Column(j).Equal(tr.Row(j)) {
+					t.Errorf("Column(%d) = %+v, Transpose().Row(%d) = %+v", j, m.Column(j), j, tr.Row(j))
+				}
+			}
+			if !reflect.DeepEqual(tr.Transpose(), m) {
+				t.Error("Transpose().Transpose() differs from the original matrix")
+			}
+		})
+	}
+}
+
+func TestBitMatrix_Counts(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	m := randomBitMatrix(r, 33, 130)
+
+	rowCounts := m.RowCounts()
+	for i := uint64(0); i < m.Rows(); i++ {
+		if rowCounts[i] != m.Row(i).Count() {
+			t.Errorf("RowCounts()[%d] = %d, wanted %d", i, rowCounts[i], m.Row(i).Count())
+		}
+	}
+	colCounts := m.ColumnCounts()
+	for j := uint64(0); j < m.Cols(); j++ {
+		if colCounts[j] != m.Column(j).Count() {
+			t.Errorf("ColumnCounts()[%d] = %d, wanted %d", j, colCounts[j], m.Column(j).Count())
+		}
+	}
+}
+
+func TestBitMatrix_OrAndRows(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	m := randomBitMatrix(r, 5, 130)
+	// Make sure some columns are fully set.
+	for i := uint64(0); i < m.Rows(); i++ {
+		m.SetBitAt(i, 7, true)
+		m.SetBitAt(i, 129, true)
+	}
+
+	or, and := m.OrRows(), m.AndRows()
+	for j := uint64(0); j < m.Cols(); j++ {
+		col := m.Column(j).Count()
+		if or.BitAt(j) != (col > 0) {
+			t.Errorf("OrRows().BitAt(%d) = %t, column count %d", j, or.BitAt(j), col)
+		}
+		if and.BitAt(j) != (col == m.Rows()) {
+			t.Errorf("AndRows().BitAt(%d) = %t, column count %d", j, and.BitAt(j), col)
+		}
+	}
+
+	t.Run("no rows", func(t *testing.T) {
+		m := NewBitMatrix(0, 70)
+		if got := m.OrRows(); got.Len() != 70 || got.Count() != 0 {
+			t.Errorf("OrRows() = %+v, wanted empty", got)
+		}
+		if got := m.AndRows(); got.Len() != 70 || got.Count() != 70 {
+			t.Errorf("AndRows() = %+v, wanted all bits set", got)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package bitfield
 
 import (
 	"encoding/binary"
+	"math/big"
 	"math/bits"
 )
 
@@ -20,6 +21,32 @@ func NewBitvector128() Bitvector128 {
 	return byteArray[:]
 }
 
+// NewBitvector128FromBytes creates a new bitvector holding a copy of given bytes, e.g. decoded from
+// untrusted input. This method will return an error if the input is not 16 bytes.
+func NewBitvector128FromBytes(b []byte) (Bitvector128, error) {
+	if len(b) != bitvector128ByteSize {
+		return nil, ErrWrongLen
+	}
+	ret := make(Bitvector128, bitvector128ByteSize)
+	copy(ret, b)
+	return ret, nil
+}
+
+// NewBitvector128FromBools creates a new bitvector with bits set to given values.
+// This method will return an error if the number of values is not 128.
+func NewBitvector128FromBools(bools []bool) (Bitvector128, error) {
+	if uint64(len(bools)) != bitvector128BitSize {
+		return nil, ErrWrongLen
+	}
+	return boolsToBytes(bools), nil
+}
+
+// NewBitvector128FromBigInt creates a new bitvector holding bits of a given integer, in the bit order of
+// BitAt. This method will return an error if the integer is negative or exceeds 128 bits.
+func NewBitvector128FromBigInt(x *big.Int) (Bitvector128, error) {
+	return bigIntToBytes(bitvector128BitSize, x)
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitvector, then this method returns false.
 func (b Bitvector128) BitAt(idx uint64) bool {
@@ -82,7 +109,7 @@ func (b Bitvector128) Bytes() []byte {
 
 // Shift bitvector by i. If i >= 0, perform left shift, otherwise right shift.
 func (b Bitvector128) Shift(i int) {
-	if len(b) == 0 {
+	if len(b) != bitvector128ByteSize {
 		return
 	}
 
@@ -124,8 +151,8 @@ func (b Bitvector128) BitIndices() []int {
 // Contains returns true if the bitlist contains all of the bits from the provided argument
 // bitlist. This method will return an error if bitlists are not the same length.
 func (b Bitvector128) Contains(c Bitvector128) (bool, error) {
-	if b.Len() != c.Len() {
-		return false, ErrBitvectorDifferentLength
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return false, err
 	}
 
 	// To ensure all of the bits in c are present in b, we iterate over every byte, combine
@@ -143,13 +170,8 @@ func (b Bitvector128) Contains(c Bitvector128) (bool, error) {
 // Overlaps returns true if the bitlist contains one of the bits from the provided argument
 // bitlist. This method will return an error if bitlists are not the same length.
 func (b Bitvector128) Overlaps(c Bitvector128) (bool, error) {
-	lenB, lenC := b.Len(), c.Len()
-	if b.Len() != c.Len() {
-		return false, ErrBitvectorDifferentLength
-	}
-
-	if lenB == 0 || lenC == 0 {
-		return false, nil
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return false, err
 	}
 
 	// To ensure all of the bits in c are not overlapped in b, we iterate over every byte, invert b
@@ -168,8 +190,8 @@ func (b Bitvector128) Overlaps(c Bitvector128) (bool, error) {
 
 // Or returns the OR result of the two bitfields. This method will return an error if the bitlists are not the same length.
 func (b Bitvector128) Or(c Bitvector128) (Bitvector128, error) {
-	if b.Len() != c.Len() {
-		return nil, ErrBitvectorDifferentLength
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return nil, err
 	}
 
 	ret := make([]byte, len(b))
@@ -179,3 +201,92 @@ func (b Bitvector128) Or(c Bitvector128) (Bitvector128, error) {
 
 	return ret, nil
 }
+
+// Equal returns true if both bitvectors have the same bits set.
+func (b Bitvector128) Equal(c Bitvector128) bool {
+	return Equal(b, c)
+}
+
+// Compare returns an integer comparing two bitvectors lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c.
+func (b Bitvector128) Compare(c Bitvector128) int {
+	return Compare(b, c)
+}
+
+// Hash returns a stable 64-bit hash of the bitvector. Equal bitfields have equal hashes,
+// regardless of their type.
+func (b Bitvector128) Hash() uint64 {
+	return Hash(b)
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector128) HammingDistance(c Bitvector128) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return 0, err
+	}
+	return xorCountBits(b, c, bitvector128BitSize), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector128) IntersectionSize(c Bitvector128) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return 0, err
+	}
+	and, _ := andOrCountBits(b, c, bitvector128BitSize)
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector128) UnionSize(c Bitvector128) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return 0, err
+	}
+	_, or := andOrCountBits(b, c, bitvector128BitSize)
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector128) Jaccard(c Bitvector128) (float64, error) {
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return 0, err
+	}
+	return jaccard(andOrCountBits(b, c, bitvector128BitSize)), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector128) IsSubsetOf(c Bitvector128) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return false, err
+	}
+	return isSubsetBits(b, c, bitvector128BitSize), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector128) IsDisjoint(c Bitvector128) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector128ByteSize); err != nil {
+		return false, err
+	}
+	return isDisjointBits(b, c, bitvector128BitSize), nil
+}
+
+// ToBools returns bits of the bitvector as an array of booleans.
+func (b Bitvector128) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitvector as a non-negative integer, in the bit order of BitAt.
+func (b Bitvector128) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
+// Words returns bits of the bitvector as an array of words, in the layout used by Bitlist64.
+func (b Bitvector128) Words() []uint64 {
+	return bitfieldWords(b)
+}
@@ -304,44 +304,49 @@ func TestBitvector128_Shift(t *testing.T) {
 		want      Bitvector128
 	}{
 		{
-			bitvector: Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			bitvector: Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 15: 0x00},
 			shift:     1,
-			want:      Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			want:      Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 15: 0x00},
 		},
 		{
-			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 15: 0x00},
 			shift:     1,
-			want:      Bitvector128{0x02, 0x47, 0xC5, 0xFD, 0xBB, 0x59, 0x5B, 0x5A},
+			want:      Bitvector128{0x02, 0x47, 0xC5, 0xFD, 0xBB, 0x59, 0x5B, 0x5A, 15: 0x00},
 		},
 		{
-			bitvector: Bitvector128{0x23, 0x01, 0xAD, 0xE2, 0xDD, 0xFE, 0xAC, 0xAD},
+			bitvector: Bitvector128{0x23, 0x01, 0xAD, 0xE2, 0xDD, 0xFE, 0xAC, 0xAD, 15: 0x00},
 			shift:     1,
-			want:      Bitvector128{0x46, 0x03, 0x5b, 0xc5, 0xBB, 0xFD, 0x59, 0x5A},
+			want:      Bitvector128{0x46, 0x03, 0x5b, 0xc5, 0xBB, 0xFD, 0x59, 0x5A, 15: 0x00},
 		},
 		{
-			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 15: 0x00},
 			shift:     -1,
-			want:      Bitvector128{0x00, 0x91, 0xf1, 0x7f, 0x6e, 0xd6, 0x56, 0xd6},
+			want:      Bitvector128{0x00, 0x91, 0xf1, 0x7f, 0x6e, 0xd6, 0x56, 0xd6, 15: 0x00},
 		},
 		{
-			bitvector: Bitvector128{0xd6, 0x23, 0x6e, 0x91, 0xDD, 0xAC, 0x7f, 0xE2},
+			bitvector: Bitvector128{0xd6, 0x23, 0x6e, 0x91, 0xDD, 0xAC, 0x7f, 0xE2, 15: 0x00},
 			shift:     -1,
-			want:      Bitvector128{0x6b, 0x11, 0xb7, 0x48, 0xee, 0xd6, 0x3f, 0xf1},
+			want:      Bitvector128{0x6b, 0x11, 0xb7, 0x48, 0xee, 0xd6, 0x3f, 0xf1, 15: 0x00},
 		},
 		{
-			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 15: 0x00},
 			shift:     3,
-			want:      Bitvector128{0x09, 0x1f, 0x17, 0xf6, 0xed, 0x65, 0x6d, 0x68},
+			want:      Bitvector128{0x09, 0x1f, 0x17, 0xf6, 0xed, 0x65, 0x6d, 0x68, 15: 0x00},
 		},
 		{
-			bitvector: Bitvector128{0x17, 0xDD, 0x09, 0x17, 0x1f, 0x17, 0xf6, 0xed},
+			bitvector: Bitvector128{0x17, 0xDD, 0x09, 0x17, 0x1f, 0x17, 0xf6, 0xed, 15: 0x00},
 			shift:     -3,
-			want:      Bitvector128{0x02, 0xfb, 0xa1, 0x22, 0xe3, 0xe2, 0xfe, 0xdd},
+			want:      Bitvector128{0x02, 0xfb, 0xa1, 0x22, 0xe3, 0xe2, 0xfe, 0xdd, 15: 0x00},
 		},
 		{
-			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector128{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 15: 0x00},
 			shift:     8,
-			want:      Bitvector128{0x23, 0xe2, 0xfe, 0xdd, 0xac, 0xad, 0xad, 0x00},
+			want:      Bitvector128{0x23, 0xe2, 0xfe, 0xdd, 0xac, 0xad, 0xad, 0x00, 15: 0x00},
+		},
+		{
+			bitvector: Bitvector128{0x01, 0x23},
+			shift:     1,
+			want:      Bitvector128{0x01, 0x23},
 		},
 	}
 
@@ -408,53 +413,53 @@ func TestBitvector128_Contains(t *testing.T) {
 		want bool
 	}{
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x02}, // 0b00000010
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x03}, // 0b00000011
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x02, 15: 0x00}, // 0b00000010
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x03, 15: 0x00}, // 0b00000011
 			want: false,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03}, // 0b00000011
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03}, // 0b00000011
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03}, // 0b00000011
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03}, // 0b00000011
 			want: true,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13}, // 0b00010011
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x15}, // 0b00010101
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13}, // 0b00010011
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x15}, // 0b00010101
 			want: false,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F}, // 0b00011111
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13}, // 0b00010011
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F}, // 0b00011111
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13}, // 0b00010011
 			want: true,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F}, // 0b00011111
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13}, // 0b00010011
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F}, // 0b00011111
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13}, // 0b00010011
 			want: true,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F, 0x03}, // 0b00011111, 0b00000011
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13, 0x02}, // 0b00010011, 0b00000010
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F, 0x03}, // 0b00011111, 0b00000011
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13, 0x02}, // 0b00010011, 0b00000010
 			want: true,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F, 0x01}, // 0b00011111, 0b00000001
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x93, 0x01}, // 0b10010011, 0b00000001
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F, 0x01}, // 0b00011111, 0b00000001
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x93, 0x01}, // 0b10010011, 0b00000001
 			want: false,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xFF, 0x02}, // 0b11111111, 0x00000010
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13, 0x03}, // 0b00010011, 0x00000011
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xFF, 0x02}, // 0b11111111, 0x00000010
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13, 0x03}, // 0b00010011, 0x00000011
 			want: false,
 		},
 		{
-			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xFF, 0x85}, // 0b11111111, 0x10000111
-			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13, 0x8F}, // 0b00010011, 0x10001111
+			a:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xFF, 0x85}, // 0b11111111, 0x10000111
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x13, 0x8F}, // 0b00010011, 0x10001111
 			want: false,
 		},
 		{
-			a:    Bitvector128{0xFF, 0x8F}, // 0b11111111, 0x10001111
-			b:    Bitvector128{0x13, 0x83}, // 0b00010011, 0x10000011
+			a:    Bitvector128{0xFF, 0x8F, 15: 0x00}, // 0b11111111, 0x10001111
+			b:    Bitvector128{0x13, 0x83, 15: 0x00}, // 0b00010011, 0x10000011
 			want: true,
 		},
 	}
@@ -480,43 +485,43 @@ func TestBitvector128_Overlaps(t *testing.T) {
 		want bool
 	}{
 		{
-			a:    Bitvector128{0x06}, // 0b00000110
-			b:    Bitvector128{0x01}, // 0b00000101
+			a:    Bitvector128{0x06, 15: 0x00}, // 0b00000110
+			b:    Bitvector128{0x01, 15: 0x00}, // 0b00000101
 			want: false,
 		},
 		{
-			a:    Bitvector128{0x06}, // 0b00000110
-			b:    Bitvector128{0x05}, // 0b00000101
+			a:    Bitvector128{0x06, 15: 0x00}, // 0b00000110
+			b:    Bitvector128{0x05, 15: 0x00}, // 0b00000101
 			want: true,
 		},
 		{
-			a:    Bitvector128{0x1A}, // 0b00011010
-			b:    Bitvector128{0x25}, // 0b00100101
+			a:    Bitvector128{0x1A, 15: 0x00}, // 0b00011010
+			b:    Bitvector128{0x25, 15: 0x00}, // 0b00100101
 			want: false,
 		},
 		{
-			a:    Bitvector128{0x1F}, // 0b00011111
-			b:    Bitvector128{0x11}, // 0b00010001
+			a:    Bitvector128{0x1F, 15: 0x00}, // 0b00011111
+			b:    Bitvector128{0x11, 15: 0x00}, // 0b00010001
 			want: true,
 		},
 		{
-			a:    Bitvector128{0xFF, 0x85}, // 0b11111111, 0b10000111
-			b:    Bitvector128{0x13, 0x8F}, // 0b00010011, 0b10001111
+			a:    Bitvector128{0xFF, 0x85, 15: 0x00}, // 0b11111111, 0b10000111
+			b:    Bitvector128{0x13, 0x8F, 15: 0x00}, // 0b00010011, 0b10001111
 			want: true,
 		},
 		{
-			a:    Bitvector128{0x00, 0x40}, // 0b00000001, 0b01000000
-			b:    Bitvector128{0x00, 0x40}, // 0b00000010, 0b01000000
+			a:    Bitvector128{0x00, 0x40, 15: 0x00}, // 0b00000001, 0b01000000
+			b:    Bitvector128{0x00, 0x40, 15: 0x00}, // 0b00000010, 0b01000000
 			want: true,
 		},
 		{
-			a:    Bitvector128{0x01, 0x40}, // 0b00000001, 0b01000000
-			b:    Bitvector128{0x02, 0x30}, // 0b00000010, 0b01000000
+			a:    Bitvector128{0x01, 0x40, 15: 0x00}, // 0b00000001, 0b01000000
+			b:    Bitvector128{0x02, 0x30, 15: 0x00}, // 0b00000010, 0b01000000
 			want: false,
 		},
 		{
-			a:    Bitvector128{0x01, 0x01, 0x01}, // 0b00000001, 0b00000001, 0b00000001
-			b:    Bitvector128{0x02, 0x00, 0x00}, // 0b00000010, 0b00000000, 0b00000001
+			a:    Bitvector128{0x01, 0x01, 0x01, 15: 0x00}, // 0b00000001, 0b00000001, 0b00000001
+			b:    Bitvector128{0x02, 0x00, 0x00, 15: 0x00}, // 0b00000010, 0b00000000, 0b00000001
 			want: false,
 		},
 	}
@@ -542,44 +547,44 @@ func TestBitVector128_Or(t *testing.T) {
 		want Bitvector128
 	}{
 		{
-			a:    Bitvector128{0x02}, // 0b00000010
-			b:    Bitvector128{0x03}, // 0b00000011
-			want: Bitvector128{0x03}, // 0b00000011
+			a:    Bitvector128{0x02, 15: 0x00}, // 0b00000010
+			b:    Bitvector128{0x03, 15: 0x00}, // 0b00000011
+			want: Bitvector128{0x03, 15: 0x00}, // 0b00000011
 		},
 		{
-			a:    Bitvector128{0x03}, // 0b00000011
-			b:    Bitvector128{0x03}, // 0b00000011
-			want: Bitvector128{0x03}, // 0b00000011
+			a:    Bitvector128{0x03, 15: 0x00}, // 0b00000011
+			b:    Bitvector128{0x03, 15: 0x00}, // 0b00000011
+			want: Bitvector128{0x03, 15: 0x00}, // 0b00000011
 		},
 		{
-			a:    Bitvector128{0x13}, // 0b00010011
-			b:    Bitvector128{0x15}, // 0b00010101
-			want: Bitvector128{0x17}, // 0b00010111
+			a:    Bitvector128{0x13, 15: 0x00}, // 0b00010011
+			b:    Bitvector128{0x15, 15: 0x00}, // 0b00010101
+			want: Bitvector128{0x17, 15: 0x00}, // 0b00010111
 		},
 		{
-			a:    Bitvector128{0x1F}, // 0b00011111
-			b:    Bitvector128{0x13}, // 0b00010011
-			want: Bitvector128{0x1F}, // 0b00011111
+			a:    Bitvector128{0x1F, 15: 0x00}, // 0b00011111
+			b:    Bitvector128{0x13, 15: 0x00}, // 0b00010011
+			want: Bitvector128{0x1F, 15: 0x00}, // 0b00011111
 		},
 		{
-			a:    Bitvector128{0x1F, 0x03}, // 0b00011111, 0b00000011
-			b:    Bitvector128{0x13, 0x02}, // 0b00010011, 0b00000010
-			want: Bitvector128{0x1F, 0x03}, // 0b00011111, 0b00000011
+			a:    Bitvector128{0x1F, 0x03, 15: 0x00}, // 0b00011111, 0b00000011
+			b:    Bitvector128{0x13, 0x02, 15: 0x00}, // 0b00010011, 0b00000010
+			want: Bitvector128{0x1F, 0x03, 15: 0x00}, // 0b00011111, 0b00000011
 		},
 		{
-			a:    Bitvector128{0x1F, 0x01}, // 0b00011111, 0b00000001
-			b:    Bitvector128{0x93, 0x01}, // 0b10010011, 0b00000001
-			want: Bitvector128{0x9F, 0x01}, // 0b00011111, 0b00000001
+			a:    Bitvector128{0x1F, 0x01, 15: 0x00}, // 0b00011111, 0b00000001
+			b:    Bitvector128{0x93, 0x01, 15: 0x00}, // 0b10010011, 0b00000001
+			want: Bitvector128{0x9F, 0x01, 15: 0x00}, // 0b00011111, 0b00000001
 		},
 		{
-			a:    Bitvector128{0xFF, 0x02}, // 0b11111111, 0x00000010
-			b:    Bitvector128{0x13, 0x03}, // 0b00010011, 0x00000011
-			want: Bitvector128{0xFF, 0x03}, // 0b11111111, 0x00000011
+			a:    Bitvector128{0xFF, 0x02, 15: 0x00}, // 0b11111111, 0x00000010
+			b:    Bitvector128{0x13, 0x03, 15: 0x00}, // 0b00010011, 0x00000011
+			want: Bitvector128{0xFF, 0x03, 15: 0x00}, // 0b11111111, 0x00000011
 		},
 		{
-			a:    Bitvector128{0xFF, 0x85}, // 0b11111111, 0x10000111
-			b:    Bitvector128{0x13, 0x8F}, // 0b00010011, 0x10001111
-			want: Bitvector128{0xFF, 0x8F}, // 0b11111111, 0x10001111
+			a:    Bitvector128{0xFF, 0x85, 15: 0x00}, // 0b11111111, 0x10000111
+			b:    Bitvector128{0x13, 0x8F, 15: 0x00}, // 0b00010011, 0x10001111
+			want: Bitvector128{0xFF, 0x8F, 15: 0x00}, // 0b11111111, 0x10001111
 		},
 	}
 
@@ -596,3 +601,55 @@ func TestBitVector128_Or(t *testing.T) {
 		}
 	}
 }
+
+func TestBitvector128_FromBytes(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want Bitvector128
+		err  error
+	}{
+		{b: nil, err: ErrWrongLen},
+		{b: make([]byte, 16), want: NewBitvector128()},
+		{b: []byte{0x01, 15: 0x80}, want: Bitvector128{0x01, 15: 0x80}},
+		{b: make([]byte, 17), err: ErrWrongLen},
+		{b: make([]byte, 15), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitvector128FromBytes(tt.b)
+		if err != tt.err || !bytes.Equal(got, tt.want) {
+			t.Errorf("NewBitvector128FromBytes(%x) = %x, %v, wanted %x, %v", tt.b, got, err, tt.want, tt.err)
+		}
+		if err == nil && len(tt.b) > 0 {
+			tt.b[0] ^= 0x01
+			if bytes.Equal(got, tt.b) {
+				t.Errorf("NewBitvector128FromBytes(%x) does not copy the input", tt.b)
+			}
+		}
+	}
+}
+
+func TestBitvector128_WrongLen(t *testing.T) {
+	tests := []struct {
+		a   Bitvector128
+		b   Bitvector128
+		err error
+	}{
+		{a: NewBitvector128(), b: Bitvector128{}, err: ErrBitvectorDifferentLength},
+		{a: Bitvector128{}, b: NewBitvector128(), err: ErrBitvectorDifferentLength},
+		{a: Bitvector128{}, b: Bitvector128{}, err: ErrWrongLen},
+		{a: make(Bitvector128, 17), b: make(Bitvector128, 17), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.a.Contains(tt.b); err != tt.err {
+			t.Errorf("(%x).Contains(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+		if _, err := tt.a.Overlaps(tt.b); err != tt.err {
+			t.Errorf("(%x).Overlaps(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+		if _, err := tt.a.Or(tt.b); err != tt.err {
+			t.Errorf("(%x).Or(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+	}
+}
@@ -275,12 +275,12 @@ func TestBitvector16_Shift(t *testing.T) {
 		{
 			bitvector: Bitvector16{0x01, 0x23},
 			shift:     1,
-			want:      Bitvector16{0x02, 0x47},
+			want:      Bitvector16{0x02, 0x46},
 		},
 		{
 			bitvector: Bitvector16{0x23, 0x01},
 			shift:     1,
-			want:      Bitvector16{0x46, 0x03},
+			want:      Bitvector16{0x46, 0x02},
 		},
 		{
 			bitvector: Bitvector16{0x01, 0x23},
@@ -295,7 +295,7 @@ func TestBitvector16_Shift(t *testing.T) {
 		{
 			bitvector: Bitvector16{0x01, 0x23},
 			shift:     3,
-			want:      Bitvector16{0x09, 0x1f},
+			want:      Bitvector16{0x09, 0x18},
 		},
 		{
 			bitvector: Bitvector16{0x17, 0xDD},
@@ -305,7 +305,12 @@ func TestBitvector16_Shift(t *testing.T) {
 		{
 			bitvector: Bitvector16{0x01, 0x23},
 			shift:     8,
-			want:      Bitvector16{0x23, 0xe2},
+			want:      Bitvector16{0x23, 0x00},
+		},
+		{
+			bitvector: Bitvector16{0x01},
+			shift:     1,
+			want:      Bitvector16{0x01},
 		},
 	}
 
@@ -376,48 +381,48 @@ func TestBitvector16_Contains(t *testing.T) {
 		want bool
 	}{
 		{
-			a:    Bitvector16{0x00, 0x00, 0x00, 0x00, 0x02}, // 0b00000010
-			b:    Bitvector16{0x00, 0x00, 0x00, 0x00, 0x03}, // 0b00000011
+			a:    Bitvector16{0x00, 0x02}, // 0b00000010
+			b:    Bitvector16{0x00, 0x03}, // 0b00000011
 			want: false,
 		},
 		{
-			a:    Bitvector16{0x00, 0x00, 0x03}, // 0b00000011
-			b:    Bitvector16{0x00, 0x00, 0x03}, // 0b00000011
+			a:    Bitvector16{0x00, 0x03}, // 0b00000011
+			b:    Bitvector16{0x00, 0x03}, // 0b00000011
 			want: true,
 		},
 		{
-			a:    Bitvector16{0x00, 0x00, 0x13}, // 0b00010011
-			b:    Bitvector16{0x00, 0x00, 0x15}, // 0b00010101
+			a:    Bitvector16{0x00, 0x13}, // 0b00010011
+			b:    Bitvector16{0x00, 0x15}, // 0b00010101
 			want: false,
 		},
 		{
-			a:    Bitvector16{0x00, 0x00, 0x1F}, // 0b00011111
-			b:    Bitvector16{0x00, 0x00, 0x13}, // 0b00010011
+			a:    Bitvector16{0x00, 0x1F}, // 0b00011111
+			b:    Bitvector16{0x00, 0x13}, // 0b00010011
 			want: true,
 		},
 		{
-			a:    Bitvector16{0x00, 0x00, 0x1F}, // 0b00011111
-			b:    Bitvector16{0x00, 0x00, 0x13}, // 0b00010011
+			a:    Bitvector16{0x00, 0x1F}, // 0b00011111
+			b:    Bitvector16{0x00, 0x13}, // 0b00010011
 			want: true,
 		},
 		{
-			a:    Bitvector16{0x00, 0x1F, 0x03}, // 0b00011111, 0b00000011
-			b:    Bitvector16{0x00, 0x13, 0x02}, // 0b00010011, 0b00000010
+			a:    Bitvector16{0x1F, 0x03}, // 0b00011111, 0b00000011
+			b:    Bitvector16{0x13, 0x02}, // 0b00010011, 0b00000010
 			want: true,
 		},
 		{
-			a:    Bitvector16{0x00, 0x1F, 0x01}, // 0b00011111, 0b00000001
-			b:    Bitvector16{0x00, 0x93, 0x01}, // 0b10010011, 0b00000001
+			a:    Bitvector16{0x1F, 0x01}, // 0b00011111, 0b00000001
+			b:    Bitvector16{0x93, 0x01}, // 0b10010011, 0b00000001
 			want: false,
 		},
 		{
-			a:    Bitvector16{0x00, 0xFF, 0x02}, // 0b11111111, 0x00000010
-			b:    Bitvector16{0x00, 0x13, 0x03}, // 0b00010011, 0x00000011
+			a:    Bitvector16{0xFF, 0x02}, // 0b11111111, 0x00000010
+			b:    Bitvector16{0x13, 0x03}, // 0b00010011, 0x00000011
 			want: false,
 		},
 		{
-			a:    Bitvector16{0x00, 0xFF, 0x85}, // 0b11111111, 0x10000111
-			b:    Bitvector16{0x00, 0x13, 0x8F}, // 0b00010011, 0x10001111
+			a:    Bitvector16{0xFF, 0x85}, // 0b11111111, 0x10000111
+			b:    Bitvector16{0x13, 0x8F}, // 0b00010011, 0x10001111
 			want: false,
 		},
 		{
@@ -448,23 +453,23 @@ func TestBitvector16_Overlaps(t *testing.T) {
 		want bool
 	}{
 		{
-			a:    Bitvector16{0x06}, // 0b00000110
-			b:    Bitvector16{0x01}, // 0b00000101
+			a:    Bitvector16{0x06, 0x00}, // 0b00000110
+			b:    Bitvector16{0x01, 0x00}, // 0b00000101
 			want: false,
 		},
 		{
-			a:    Bitvector16{0x06}, // 0b00000110
-			b:    Bitvector16{0x05}, // 0b00000101
+			a:    Bitvector16{0x06, 0x00}, // 0b00000110
+			b:    Bitvector16{0x05, 0x00}, // 0b00000101
 			want: true,
 		},
 		{
-			a:    Bitvector16{0x1A}, // 0b00011010
-			b:    Bitvector16{0x25}, // 0b00100101
+			a:    Bitvector16{0x1A, 0x00}, // 0b00011010
+			b:    Bitvector16{0x25, 0x00}, // 0b00100101
 			want: false,
 		},
 		{
-			a:    Bitvector16{0x1F}, // 0b00011111
-			b:    Bitvector16{0x11}, // 0b00010001
+			a:    Bitvector16{0x1F, 0x00}, // 0b00011111
+			b:    Bitvector16{0x11, 0x00}, // 0b00010001
 			want: true,
 		},
 		{
@@ -483,8 +488,8 @@ func TestBitvector16_Overlaps(t *testing.T) {
 			want: false,
 		},
 		{
-			a:    Bitvector16{0x01, 0x01, 0x01}, // 0b00000001, 0b00000001, 0b00000001
-			b:    Bitvector16{0x02, 0x00, 0x00}, // 0b00000010, 0b00000000, 0b00000001
+			a:    Bitvector16{0x01, 0x01}, // 0b00000001, 0b00000001
+			b:    Bitvector16{0x02, 0x00}, // 0b00000010, 0b00000000
 			want: false,
 		},
 	}
@@ -510,24 +515,24 @@ func TestBitVector16_Or(t *testing.T) {
 		want Bitvector16
 	}{
 		{
-			a:    Bitvector16{0x02}, // 0b00000010
-			b:    Bitvector16{0x03}, // 0b00000011
-			want: Bitvector16{0x03}, // 0b00000011
+			a:    Bitvector16{0x02, 0x00}, // 0b00000010
+			b:    Bitvector16{0x03, 0x00}, // 0b00000011
+			want: Bitvector16{0x03, 0x00}, // 0b00000011
 		},
 		{
-			a:    Bitvector16{0x03}, // 0b00000011
-			b:    Bitvector16{0x03}, // 0b00000011
-			want: Bitvector16{0x03}, // 0b00000011
+			a:    Bitvector16{0x03, 0x00}, // 0b00000011
+			b:    Bitvector16{0x03, 0x00}, // 0b00000011
+			want: Bitvector16{0x03, 0x00}, // 0b00000011
 		},
 		{
-			a:    Bitvector16{0x13}, // 0b00010011
-			b:    Bitvector16{0x15}, // 0b00010101
-			want: Bitvector16{0x17}, // 0b00010111
+			a:    Bitvector16{0x13, 0x00}, // 0b00010011
+			b:    Bitvector16{0x15, 0x00}, // 0b00010101
+			want: Bitvector16{0x17, 0x00}, // 0b00010111
 		},
 		{
-			a:    Bitvector16{0x1F}, // 0b00011111
-			b:    Bitvector16{0x13}, // 0b00010011
-			want: Bitvector16{0x1F}, // 0b00011111
+			a:    Bitvector16{0x1F, 0x00}, // 0b00011111
+			b:    Bitvector16{0x13, 0x00}, // 0b00010011
+			want: Bitvector16{0x1F, 0x00}, // 0b00011111
 		},
 		{
 			a:    Bitvector16{0x1F, 0x03}, // 0b00011111, 0b00000011
@@ -564,3 +569,55 @@ func TestBitVector16_Or(t *testing.T) {
 		}
 	}
 }
+
+func TestBitvector16_FromBytes(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want Bitvector16
+		err  error
+	}{
+		{b: nil, err: ErrWrongLen},
+		{b: make([]byte, 2), want: NewBitvector16()},
+		{b: []byte{0xa5, 0xa4}, want: Bitvector16{0xa5, 0xa4}},
+		{b: make([]byte, 3), err: ErrWrongLen},
+		{b: make([]byte, 1), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitvector16FromBytes(tt.b)
+		if err != tt.err || !bytes.Equal(got, tt.want) {
+			t.Errorf("NewBitvector16FromBytes(%x) = %x, %v, wanted %x, %v", tt.b, got, err, tt.want, tt.err)
+		}
+		if err == nil && len(tt.b) > 0 {
+			tt.b[0] ^= 0x01
+			if bytes.Equal(got, tt.b) {
+				t.Errorf("NewBitvector16FromBytes(%x) does not copy the input", tt.b)
+			}
+		}
+	}
+}
+
+func TestBitvector16_WrongLen(t *testing.T) {
+	tests := []struct {
+		a   Bitvector16
+		b   Bitvector16
+		err error
+	}{
+		{a: NewBitvector16(), b: Bitvector16{}, err: ErrBitvectorDifferentLength},
+		{a: Bitvector16{}, b: NewBitvector16(), err: ErrBitvectorDifferentLength},
+		{a: Bitvector16{}, b: Bitvector16{}, err: ErrWrongLen},
+		{a: make(Bitvector16, 3), b: make(Bitvector16, 3), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.a.Contains(tt.b); err != tt.err {
+			t.Errorf("(%x).Contains(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+		if _, err := tt.a.Overlaps(tt.b); err != tt.err {
+			t.Errorf("(%x).Overlaps(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+		if _, err := tt.a.Or(tt.b); err != tt.err {
+			t.Errorf("(%x).Or(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package bitfield
 
 import (
 	"encoding/binary"
+	"math/big"
 	"math/bits"
 )
 
@@ -20,6 +21,32 @@ func NewBitvector256() Bitvector256 {
 	return byteArray[:]
 }
 
+// NewBitvector256FromBytes creates a new bitvector holding a copy of given bytes, e.g. decoded from
+// untrusted input. This method will return an error if the input is not 32 bytes.
+func NewBitvector256FromBytes(b []byte) (Bitvector256, error) {
+	if len(b) != bitvector256ByteSize {
+		return nil, ErrWrongLen
+	}
+	ret := make(Bitvector256, bitvector256ByteSize)
+	copy(ret, b)
+	return ret, nil
+}
+
+// NewBitvector256FromBools creates a new bitvector with bits set to given values.
+// This method will return an error if the number of values is not 256.
+func NewBitvector256FromBools(bools []bool) (Bitvector256, error) {
+	if uint64(len(bools)) != bitvector256BitSize {
+		return nil, ErrWrongLen
+	}
+	return boolsToBytes(bools), nil
+}
+
+// NewBitvector256FromBigInt creates a new bitvector holding bits of a given integer, in the bit order of
+// BitAt. This method will return an error if the integer is negative or exceeds 256 bits.
+func NewBitvector256FromBigInt(x *big.Int) (Bitvector256, error) {
+	return bigIntToBytes(bitvector256BitSize, x)
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitvector, then this method returns false.
 func (b Bitvector256) BitAt(idx uint64) bool {
@@ -82,7 +109,7 @@ func (b Bitvector256) Bytes() []byte {
 
 // Shift bitvector by i. If i >= 0, perform left shift, otherwise right shift.
 func (b Bitvector256) Shift(i int) {
-	if len(b) == 0 {
+	if len(b) != bitvector256ByteSize {
 		return
 	}
 
@@ -120,3 +147,92 @@ func (b Bitvector256) BitIndices() []int {
 
 	return indices
 }
+
+// Equal returns true if both bitvectors have the same bits set.
+func (b Bitvector256) Equal(c Bitvector256) bool {
+	return Equal(b, c)
+}
+
+// Compare returns an integer comparing two bitvectors lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c.
+func (b Bitvector256) Compare(c Bitvector256) int {
+	return Compare(b, c)
+}
+
+// Hash returns a stable 64-bit hash of the bitvector. Equal bitfields have equal hashes,
+// regardless of their type.
+func (b Bitvector256) Hash() uint64 {
+	return Hash(b)
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector256) HammingDistance(c Bitvector256) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector256ByteSize); err != nil {
+		return 0, err
+	}
+	return xorCountBits(b, c, bitvector256BitSize), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector256) IntersectionSize(c Bitvector256) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector256ByteSize); err != nil {
+		return 0, err
+	}
+	and, _ := andOrCountBits(b, c, bitvector256BitSize)
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector256) UnionSize(c Bitvector256) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector256ByteSize); err != nil {
+		return 0, err
+	}
+	_, or := andOrCountBits(b, c, bitvector256BitSize)
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector256) Jaccard(c Bitvector256) (float64, error) {
+	if err := checkVectorLen(b, c, bitvector256ByteSize); err != nil {
+		return 0, err
+	}
+	return jaccard(andOrCountBits(b, c, bitvector256BitSize)), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector256) IsSubsetOf(c Bitvector256) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector256ByteSize); err != nil {
+		return false, err
+	}
+	return isSubsetBits(b, c, bitvector256BitSize), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector256) IsDisjoint(c Bitvector256) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector256ByteSize); err != nil {
+		return false, err
+	}
+	return isDisjointBits(b, c, bitvector256BitSize), nil
+}
+
+// ToBools returns bits of the bitvector as an array of booleans.
+func (b Bitvector256) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitvector as a non-negative integer, in the bit order of BitAt.
+func (b Bitvector256) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
+// Words returns bits of the bitvector as an array of words, in the layout used by Bitlist64.
+func (b Bitvector256) Words() []uint64 {
+	return bitfieldWords(b)
+}
@@ -1,6 +1,7 @@
 package bitfield
 
 import (
+	"math/big"
 	"math/bits"
 )
 
@@ -19,6 +20,32 @@ func NewBitvector32() Bitvector32 {
 	return byteArray[:]
 }
 
+// NewBitvector32FromBytes creates a new bitvector holding a copy of given bytes, e.g. decoded from
+// untrusted input. This method will return an error if the input is not 4 bytes.
+func NewBitvector32FromBytes(b []byte) (Bitvector32, error) {
+	if len(b) != bitvector32ByteSize {
+		return nil, ErrWrongLen
+	}
+	ret := make(Bitvector32, bitvector32ByteSize)
+	copy(ret, b)
+	return ret, nil
+}
+
+// NewBitvector32FromBools creates a new bitvector with bits set to given values.
+// This method will return an error if the number of values is not 32.
+func NewBitvector32FromBools(bools []bool) (Bitvector32, error) {
+	if uint64(len(bools)) != bitvector32BitSize {
+		return nil, ErrWrongLen
+	}
+	return boolsToBytes(bools), nil
+}
+
+// NewBitvector32FromBigInt creates a new bitvector holding bits of a given integer, in the bit order of
+// BitAt. This method will return an error if the integer is negative or exceeds 32 bits.
+func NewBitvector32FromBigInt(x *big.Int) (Bitvector32, error) {
+	return bigIntToBytes(bitvector32BitSize, x)
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitvector, then this method returns false.
 func (b Bitvector32) BitAt(idx uint64) bool {
@@ -96,3 +123,92 @@ func (b Bitvector32) BitIndices() []int {
 
 	return indices
 }
+
+// Equal returns true if both bitvectors have the same bits set.
+func (b Bitvector32) Equal(c Bitvector32) bool {
+	return Equal(b, c)
+}
+
+// Compare returns an integer comparing two bitvectors lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c.
+func (b Bitvector32) Compare(c Bitvector32) int {
+	return Compare(b, c)
+}
+
+// Hash returns a stable 64-bit hash of the bitvector. Equal bitfields have equal hashes,
+// regardless of their type.
+func (b Bitvector32) Hash() uint64 {
+	return Hash(b)
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector32) HammingDistance(c Bitvector32) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector32ByteSize); err != nil {
+		return 0, err
+	}
+	return xorCountBits(b, c, bitvector32BitSize), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector32) IntersectionSize(c Bitvector32) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector32ByteSize); err != nil {
+		return 0, err
+	}
+	and, _ := andOrCountBits(b, c, bitvector32BitSize)
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector32) UnionSize(c Bitvector32) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector32ByteSize); err != nil {
+		return 0, err
+	}
+	_, or := andOrCountBits(b, c, bitvector32BitSize)
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector32) Jaccard(c Bitvector32) (float64, error) {
+	if err := checkVectorLen(b, c, bitvector32ByteSize); err != nil {
+		return 0, err
+	}
+	return jaccard(andOrCountBits(b, c, bitvector32BitSize)), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector32) IsSubsetOf(c Bitvector32) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector32ByteSize); err != nil {
+		return false, err
+	}
+	return isSubsetBits(b, c, bitvector32BitSize), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector32) IsDisjoint(c Bitvector32) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector32ByteSize); err != nil {
+		return false, err
+	}
+	return isDisjointBits(b, c, bitvector32BitSize), nil
+}
+
+// ToBools returns bits of the bitvector as an array of booleans.
+func (b Bitvector32) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitvector as a non-negative integer, in the bit order of BitAt.
+func (b Bitvector32) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
+// Words returns bits of the bitvector as an array of words, in the layout used by Bitlist64.
+func (b Bitvector32) Words() []uint64 {
+	return bitfieldWords(b)
+}
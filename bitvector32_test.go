@@ -311,3 +311,30 @@ func TestBitVector32_BitIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestBitvector32_FromBytes(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want Bitvector32
+		err  error
+	}{
+		{b: nil, err: ErrWrongLen},
+		{b: make([]byte, 4), want: NewBitvector32()},
+		{b: []byte{0xa5, 0xa4, 0xa7, 0xa6}, want: Bitvector32{0xa5, 0xa4, 0xa7, 0xa6}},
+		{b: make([]byte, 5), err: ErrWrongLen},
+		{b: make([]byte, 3), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitvector32FromBytes(tt.b)
+		if err != tt.err || !bytes.Equal(got, tt.want) {
+			t.Errorf("NewBitvector32FromBytes(%x) = %x, %v, wanted %x, %v", tt.b, got, err, tt.want, tt.err)
+		}
+		if err == nil && len(tt.b) > 0 {
+			tt.b[0] ^= 0x01
+			if bytes.Equal(got, tt.b) {
+				t.Errorf("NewBitvector32FromBytes(%x) does not copy the input", tt.b)
+			}
+		}
+	}
+}
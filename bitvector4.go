@@ -1,6 +1,7 @@
 package bitfield
 
 import (
+	"math/big"
 	"math/bits"
 )
 
@@ -19,6 +20,31 @@ func NewBitvector4() Bitvector4 {
 	return byteArray[:]
 }
 
+// NewBitvector4FromBytes creates a new bitvector holding a copy of given bytes, e.g. decoded from
+// untrusted input. This method will return an error if the input is not 1 byte, or has bits
+// above the length of the bitvector set.
+func NewBitvector4FromBytes(b []byte) (Bitvector4, error) {
+	if len(b) != bitvector4ByteSize || b[0]&^0x0F != 0 {
+		return nil, ErrWrongLen
+	}
+	return Bitvector4{b[0]}, nil
+}
+
+// NewBitvector4FromBools creates a new bitvector with bits set to given values.
+// This method will return an error if the number of values is not 4.
+func NewBitvector4FromBools(bools []bool) (Bitvector4, error) {
+	if uint64(len(bools)) != bitvector4BitSize {
+		return nil, ErrWrongLen
+	}
+	return boolsToBytes(bools), nil
+}
+
+// NewBitvector4FromBigInt creates a new bitvector holding bits of a given integer, in the bit order of
+// BitAt. This method will return an error if the integer is negative or exceeds 4 bits.
+func NewBitvector4FromBigInt(x *big.Int) (Bitvector4, error) {
+	return bigIntToBytes(bitvector4BitSize, x)
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitvector, then this method returns false.
 func (b Bitvector4) BitAt(idx uint64) bool {
@@ -73,7 +99,7 @@ func (b Bitvector4) Bytes() []byte {
 
 // Shift bitvector by i. If i >= 0, perform left shift, otherwise right shift.
 func (b Bitvector4) Shift(i int) {
-	if len(b) == 0 {
+	if len(b) != bitvector4ByteSize {
 		return
 	}
 
@@ -95,7 +121,7 @@ func (b Bitvector4) Shift(i int) {
 // BitIndices returns the list of indices that are set to 1.
 func (b Bitvector4) BitIndices() []int {
 	indices := make([]int, 0, 4)
-	for i, bt := range b {
+	for i, bt := range b.Bytes() {
 		for j := 0; j < 8; j++ {
 			bit := byte(1 << uint(j))
 			if bt&bit == bit {
@@ -106,3 +132,128 @@ func (b Bitvector4) BitIndices() []int {
 
 	return indices
 }
+
+// Equal returns true if both bitvectors have the same bits set.
+func (b Bitvector4) Equal(c Bitvector4) bool {
+	return Equal(b, c)
+}
+
+// Compare returns an integer comparing two bitvectors lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c.
+func (b Bitvector4) Compare(c Bitvector4) int {
+	return Compare(b, c)
+}
+
+// Hash returns a stable 64-bit hash of the bitvector. Equal bitfields have equal hashes,
+// regardless of their type.
+func (b Bitvector4) Hash() uint64 {
+	return Hash(b)
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector4) HammingDistance(c Bitvector4) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector4ByteSize); err != nil {
+		return 0, err
+	}
+	return xorCountBits(b, c, bitvector4BitSize), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector4) IntersectionSize(c Bitvector4) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector4ByteSize); err != nil {
+		return 0, err
+	}
+	and, _ := andOrCountBits(b, c, bitvector4BitSize)
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector4) UnionSize(c Bitvector4) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector4ByteSize); err != nil {
+		return 0, err
+	}
+	_, or := andOrCountBits(b, c, bitvector4BitSize)
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector4) Jaccard(c Bitvector4) (float64, error) {
+	if err := checkVectorLen(b, c, bitvector4ByteSize); err != nil {
+		return 0, err
+	}
+	return jaccard(andOrCountBits(b, c, bitvector4BitSize)), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector4) IsSubsetOf(c Bitvector4) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector4ByteSize); err != nil {
+		return false, err
+	}
+	return isSubsetBits(b, c, bitvector4BitSize), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector4) IsDisjoint(c Bitvector4) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector4ByteSize); err != nil {
+		return false, err
+	}
+	return isDisjointBits(b, c, bitvector4BitSize), nil
+}
+
+// ShiftIn shifts all bits by one towards higher indices, dropping bit 3, and sets bit 0 to the
+// given value. This is how justification bits are rotated at every epoch transition.
+func (b Bitvector4) ShiftIn(bit bool) {
+	if len(b) != bitvector4ByteSize {
+		return
+	}
+
+	b[0] = (b[0] << 1) & 0x0F
+	if bit {
+		b[0] |= 0x01
+	}
+}
+
+// AllSetInRange returns true if all bits in the half-open range [lo, hi) are set, mirroring
+// `all(bits[lo:hi])` of the justification checks. An empty range is never considered set.
+func (b Bitvector4) AllSetInRange(lo, hi uint64) bool {
+	if lo >= hi || hi > bitvector4BitSize || len(b) != bitvector4ByteSize {
+		return false
+	}
+	w := b.Window(lo, hi)
+	return w == uint8(1<<(hi-lo))-1
+}
+
+// Window returns bits in the half-open range [lo, hi) as an integer, with bit lo moved into the
+// least significant position e.g. Window(1, 4) == 0b111 when bits 1, 2 and 3 are set. Bits outside
+// of the bitvector are returned as zeros.
+func (b Bitvector4) Window(lo, hi uint64) uint8 {
+	if lo >= hi || lo >= bitvector4BitSize || len(b) != bitvector4ByteSize {
+		return 0
+	}
+	if hi > bitvector4BitSize {
+		hi = bitvector4BitSize
+	}
+	return ((b[0] & 0x0F) >> lo) & uint8(1<<(hi-lo)-1)
+}
+
+// ToBools returns bits of the bitvector as an array of booleans.
+func (b Bitvector4) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitvector as a non-negative integer, in the bit order of BitAt.
+func (b Bitvector4) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
+// Words returns bits of the bitvector as an array of words, in the layout used by Bitlist64.
+func (b Bitvector4) Words() []uint64 {
+	return bitfieldWords(b)
+}
@@ -395,3 +395,136 @@ func TestBitvector4_BitIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestBitvector4_ShiftIn(t *testing.T) {
+	tests := []struct {
+		bitvector Bitvector4
+		bit       bool
+		want      Bitvector4
+	}{
+		{
+			bitvector: Bitvector4{0x00},
+			bit:       false,
+			want:      Bitvector4{0x00},
+		},
+		{
+			bitvector: Bitvector4{0x00},
+			bit:       true,
+			want:      Bitvector4{0x01},
+		},
+		{
+			bitvector: Bitvector4{0x0B}, // 0b1011
+			bit:       false,
+			want:      Bitvector4{0x06}, // 0b0110
+		},
+		{
+			bitvector: Bitvector4{0x0F},
+			bit:       true,
+			want:      Bitvector4{0x0F},
+		},
+		{
+			bitvector: Bitvector4{0xF4}, // Bits above 4 are dropped.
+			bit:       true,
+			want:      Bitvector4{0x09},
+		},
+		{
+			bitvector: Bitvector4{},
+			bit:       true,
+			want:      Bitvector4{},
+		},
+	}
+
+	for _, tt := range tests {
+		original := make(Bitvector4, len(tt.bitvector))
+		copy(original, tt.bitvector)
+
+		tt.bitvector.ShiftIn(tt.bit)
+		if !bytes.Equal(tt.bitvector, tt.want) {
+			t.Errorf("(%x).ShiftIn(%t) = %x, wanted %x", original, tt.bit, tt.bitvector, tt.want)
+		}
+	}
+
+	t.Run("justification", func(t *testing.T) {
+		// Previous epoch justified, then current epoch justified on the next transition.
+		bits := NewBitvector4()
+		bits.ShiftIn(false)
+		bits.SetBitAt(1, true)
+		bits.ShiftIn(true)
+		if !bits.AllSetInRange(0, 1) || !bits.BitAt(2) || bits.BitAt(1) {
+			t.Errorf("Justification bits = %04b, wanted 0101", bits[0])
+		}
+	})
+}
+
+func TestBitvector4_AllSetInRange(t *testing.T) {
+	tests := []struct {
+		bitvector Bitvector4
+		lo, hi    uint64
+		want      bool
+	}{
+		{bitvector: Bitvector4{0x0E}, lo: 1, hi: 4, want: true},
+		{bitvector: Bitvector4{0x0E}, lo: 0, hi: 4, want: false},
+		{bitvector: Bitvector4{0x06}, lo: 1, hi: 3, want: true},
+		{bitvector: Bitvector4{0x06}, lo: 1, hi: 4, want: false},
+		{bitvector: Bitvector4{0x07}, lo: 0, hi: 3, want: true},
+		{bitvector: Bitvector4{0x03}, lo: 0, hi: 2, want: true},
+		{bitvector: Bitvector4{0x0F}, lo: 2, hi: 2, want: false},
+		{bitvector: Bitvector4{0x0F}, lo: 3, hi: 5, want: false},
+		{bitvector: Bitvector4{0xFF, 0xFF}, lo: 0, hi: 1, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.bitvector.AllSetInRange(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("(%x).AllSetInRange(%d, %d) = %t, wanted %t", tt.bitvector, tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestBitvector4_Window(t *testing.T) {
+	tests := []struct {
+		bitvector Bitvector4
+		lo, hi    uint64
+		want      uint8
+	}{
+		{bitvector: Bitvector4{0x0E}, lo: 1, hi: 4, want: 0x07},
+		{bitvector: Bitvector4{0x0E}, lo: 0, hi: 4, want: 0x0E},
+		{bitvector: Bitvector4{0x0A}, lo: 1, hi: 3, want: 0x01},
+		{bitvector: Bitvector4{0xFA}, lo: 2, hi: 8, want: 0x02},
+		{bitvector: Bitvector4{0x0F}, lo: 2, hi: 2, want: 0x00},
+		{bitvector: Bitvector4{0x0F}, lo: 4, hi: 5, want: 0x00},
+		{bitvector: Bitvector4{}, lo: 0, hi: 4, want: 0x00},
+	}
+
+	for _, tt := range tests {
+		if got := tt.bitvector.Window(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("(%x).Window(%d, %d) = %x, wanted %x", tt.bitvector, tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestBitvector4_FromBytes(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want Bitvector4
+		err  error
+	}{
+		{b: nil, err: ErrWrongLen},
+		{b: make([]byte, 1), want: NewBitvector4()},
+		{b: []byte{0x0f}, want: Bitvector4{0x0f}},
+		{b: []byte{0x10}, err: ErrWrongLen},
+		{b: make([]byte, 2), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitvector4FromBytes(tt.b)
+		if err != tt.err || !bytes.Equal(got, tt.want) {
+			t.Errorf("NewBitvector4FromBytes(%x) = %x, %v, wanted %x, %v", tt.b, got, err, tt.want, tt.err)
+		}
+		if err == nil && len(tt.b) > 0 {
+			tt.b[0] ^= 0x01
+			if bytes.Equal(got, tt.b) {
+				t.Errorf("NewBitvector4FromBytes(%x) does not copy the input", tt.b)
+			}
+		}
+	}
+}
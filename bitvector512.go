@@ -2,6 +2,7 @@ package bitfield
 
 import (
 	"encoding/binary"
+	"math/big"
 	"math/bits"
 )
 
@@ -20,6 +21,32 @@ func NewBitvector512() Bitvector512 {
 	return byteArray[:]
 }
 
+// NewBitvector512FromBytes creates a new bitvector holding a copy of given bytes, e.g. decoded from
+// untrusted input. This method will return an error if the input is not 64 bytes.
+func NewBitvector512FromBytes(b []byte) (Bitvector512, error) {
+	if len(b) != bitvector512ByteSize {
+		return nil, ErrWrongLen
+	}
+	ret := make(Bitvector512, bitvector512ByteSize)
+	copy(ret, b)
+	return ret, nil
+}
+
+// NewBitvector512FromBools creates a new bitvector with bits set to given values.
+// This method will return an error if the number of values is not 512.
+func NewBitvector512FromBools(bools []bool) (Bitvector512, error) {
+	if uint64(len(bools)) != bitvector512BitSize {
+		return nil, ErrWrongLen
+	}
+	return boolsToBytes(bools), nil
+}
+
+// NewBitvector512FromBigInt creates a new bitvector holding bits of a given integer, in the bit order of
+// BitAt. This method will return an error if the integer is negative or exceeds 512 bits.
+func NewBitvector512FromBigInt(x *big.Int) (Bitvector512, error) {
+	return bigIntToBytes(bitvector512BitSize, x)
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitvector, then this method returns false.
 func (b Bitvector512) BitAt(idx uint64) bool {
@@ -82,7 +109,7 @@ func (b Bitvector512) Bytes() []byte {
 
 // Shift bitvector by i. If i >= 0, perform left shift, otherwise right shift.
 func (b Bitvector512) Shift(i int) {
-	if len(b) == 0 {
+	if len(b) != bitvector512ByteSize {
 		return
 	}
 
@@ -120,3 +147,92 @@ func (b Bitvector512) BitIndices() []int {
 
 	return indices
 }
+
+// Equal returns true if both bitvectors have the same bits set.
+func (b Bitvector512) Equal(c Bitvector512) bool {
+	return Equal(b, c)
+}
+
+// Compare returns an integer comparing two bitvectors lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c.
+func (b Bitvector512) Compare(c Bitvector512) int {
+	return Compare(b, c)
+}
+
+// Hash returns a stable 64-bit hash of the bitvector. Equal bitfields have equal hashes,
+// regardless of their type.
+func (b Bitvector512) Hash() uint64 {
+	return Hash(b)
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector512) HammingDistance(c Bitvector512) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector512ByteSize); err != nil {
+		return 0, err
+	}
+	return xorCountBits(b, c, bitvector512BitSize), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector512) IntersectionSize(c Bitvector512) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector512ByteSize); err != nil {
+		return 0, err
+	}
+	and, _ := andOrCountBits(b, c, bitvector512BitSize)
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector512) UnionSize(c Bitvector512) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector512ByteSize); err != nil {
+		return 0, err
+	}
+	_, or := andOrCountBits(b, c, bitvector512BitSize)
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector512) Jaccard(c Bitvector512) (float64, error) {
+	if err := checkVectorLen(b, c, bitvector512ByteSize); err != nil {
+		return 0, err
+	}
+	return jaccard(andOrCountBits(b, c, bitvector512BitSize)), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector512) IsSubsetOf(c Bitvector512) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector512ByteSize); err != nil {
+		return false, err
+	}
+	return isSubsetBits(b, c, bitvector512BitSize), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector512) IsDisjoint(c Bitvector512) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector512ByteSize); err != nil {
+		return false, err
+	}
+	return isDisjointBits(b, c, bitvector512BitSize), nil
+}
+
+// ToBools returns bits of the bitvector as an array of booleans.
+func (b Bitvector512) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitvector as a non-negative integer, in the bit order of BitAt.
+func (b Bitvector512) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
+// Words returns bits of the bitvector as an array of words, in the layout used by Bitlist64.
+func (b Bitvector512) Words() []uint64 {
+	return bitfieldWords(b)
+}
@@ -423,44 +423,49 @@ func TestBitvector512_Shift(t *testing.T) {
 		want      Bitvector512
 	}{
 		{
-			bitvector: Bitvector512{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			bitvector: Bitvector512{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 63: 0x00},
 			shift:     1,
-			want:      Bitvector512{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			want:      Bitvector512{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 63: 0x00},
 		},
 		{
-			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 63: 0x00},
 			shift:     1,
-			want:      Bitvector512{0x02, 0x47, 0xC5, 0xFD, 0xBB, 0x59, 0x5B, 0x5A},
+			want:      Bitvector512{0x02, 0x47, 0xC5, 0xFD, 0xBB, 0x59, 0x5B, 0x5A, 63: 0x00},
 		},
 		{
-			bitvector: Bitvector512{0x23, 0x01, 0xAD, 0xE2, 0xDD, 0xFE, 0xAC, 0xAD},
+			bitvector: Bitvector512{0x23, 0x01, 0xAD, 0xE2, 0xDD, 0xFE, 0xAC, 0xAD, 63: 0x00},
 			shift:     1,
-			want:      Bitvector512{0x46, 0x03, 0x5b, 0xc5, 0xBB, 0xFD, 0x59, 0x5A},
+			want:      Bitvector512{0x46, 0x03, 0x5b, 0xc5, 0xBB, 0xFD, 0x59, 0x5A, 63: 0x00},
 		},
 		{
-			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 63: 0x00},
 			shift:     -1,
-			want:      Bitvector512{0x00, 0x91, 0xf1, 0x7f, 0x6e, 0xd6, 0x56, 0xd6},
+			want:      Bitvector512{0x00, 0x91, 0xf1, 0x7f, 0x6e, 0xd6, 0x56, 0xd6, 63: 0x00},
 		},
 		{
-			bitvector: Bitvector512{0xd6, 0x23, 0x6e, 0x91, 0xDD, 0xAC, 0x7f, 0xE2},
+			bitvector: Bitvector512{0xd6, 0x23, 0x6e, 0x91, 0xDD, 0xAC, 0x7f, 0xE2, 63: 0x00},
 			shift:     -1,
-			want:      Bitvector512{0x6b, 0x11, 0xb7, 0x48, 0xee, 0xd6, 0x3f, 0xf1},
+			want:      Bitvector512{0x6b, 0x11, 0xb7, 0x48, 0xee, 0xd6, 0x3f, 0xf1, 63: 0x00},
 		},
 		{
-			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 63: 0x00},
 			shift:     3,
-			want:      Bitvector512{0x09, 0x1f, 0x17, 0xf6, 0xed, 0x65, 0x6d, 0x68},
+			want:      Bitvector512{0x09, 0x1f, 0x17, 0xf6, 0xed, 0x65, 0x6d, 0x68, 63: 0x00},
 		},
 		{
-			bitvector: Bitvector512{0x17, 0xDD, 0x09, 0x17, 0x1f, 0x17, 0xf6, 0xed},
+			bitvector: Bitvector512{0x17, 0xDD, 0x09, 0x17, 0x1f, 0x17, 0xf6, 0xed, 63: 0x00},
 			shift:     -3,
-			want:      Bitvector512{0x02, 0xfb, 0xa1, 0x22, 0xe3, 0xe2, 0xfe, 0xdd},
+			want:      Bitvector512{0x02, 0xfb, 0xa1, 0x22, 0xe3, 0xe2, 0xfe, 0xdd, 63: 0x00},
 		},
 		{
-			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD},
+			bitvector: Bitvector512{0x01, 0x23, 0xE2, 0xFE, 0xDD, 0xAC, 0xAD, 0xAD, 63: 0x00},
 			shift:     8,
-			want:      Bitvector512{0x23, 0xe2, 0xfe, 0xdd, 0xac, 0xad, 0xad, 0x00},
+			want:      Bitvector512{0x23, 0xe2, 0xfe, 0xdd, 0xac, 0xad, 0xad, 0x00, 63: 0x00},
+		},
+		{
+			bitvector: Bitvector512{0x01, 0x23},
+			shift:     1,
+			want:      Bitvector512{0x01, 0x23},
 		},
 	}
 
@@ -529,3 +534,30 @@ func TestBitvector512_BitIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestBitvector512_FromBytes(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want Bitvector512
+		err  error
+	}{
+		{b: nil, err: ErrWrongLen},
+		{b: make([]byte, 64), want: NewBitvector512()},
+		{b: []byte{0x01, 63: 0x80}, want: Bitvector512{0x01, 63: 0x80}},
+		{b: make([]byte, 65), err: ErrWrongLen},
+		{b: make([]byte, 63), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitvector512FromBytes(tt.b)
+		if err != tt.err || !bytes.Equal(got, tt.want) {
+			t.Errorf("NewBitvector512FromBytes(%x) = %x, %v, wanted %x, %v", tt.b, got, err, tt.want, tt.err)
+		}
+		if err == nil && len(tt.b) > 0 {
+			tt.b[0] ^= 0x01
+			if bytes.Equal(got, tt.b) {
+				t.Errorf("NewBitvector512FromBytes(%x) does not copy the input", tt.b)
+			}
+		}
+	}
+}
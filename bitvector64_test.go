@@ -330,6 +330,11 @@ func TestBitvector64_Shift(t *testing.T) {
 			shift:     -256,
 			want:      Bitvector64{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
 		},
+		{
+			bitvector: Bitvector64{0x01},
+			shift:     1,
+			want:      Bitvector64{0x01},
+		},
 	}
 
 	for _, tt := range tests {
@@ -387,3 +392,30 @@ func TestBitVector64_BitIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestBitvector64_FromBytes(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want Bitvector64
+		err  error
+	}{
+		{b: nil, err: ErrWrongLen},
+		{b: make([]byte, 8), want: NewBitvector64()},
+		{b: []byte{0x01, 7: 0x80}, want: Bitvector64{0x01, 7: 0x80}},
+		{b: make([]byte, 9), err: ErrWrongLen},
+		{b: make([]byte, 7), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitvector64FromBytes(tt.b)
+		if err != tt.err || !bytes.Equal(got, tt.want) {
+			t.Errorf("NewBitvector64FromBytes(%x) = %x, %v, wanted %x, %v", tt.b, got, err, tt.want, tt.err)
+		}
+		if err == nil && len(tt.b) > 0 {
+			tt.b[0] ^= 0x01
+			if bytes.Equal(got, tt.b) {
+				t.Errorf("NewBitvector64FromBytes(%x) does not copy the input", tt.b)
+			}
+		}
+	}
+}
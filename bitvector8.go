@@ -1,6 +1,7 @@
 package bitfield
 
 import (
+	"math/big"
 	"math/bits"
 )
 
@@ -19,6 +20,32 @@ func NewBitvector8() Bitvector8 {
 	return byteArray[:]
 }
 
+// NewBitvector8FromBytes creates a new bitvector holding a copy of given bytes, e.g. decoded from
+// untrusted input. This method will return an error if the input is not 1 byte.
+func NewBitvector8FromBytes(b []byte) (Bitvector8, error) {
+	if len(b) != bitvector8ByteSize {
+		return nil, ErrWrongLen
+	}
+	ret := make(Bitvector8, bitvector8ByteSize)
+	copy(ret, b)
+	return ret, nil
+}
+
+// NewBitvector8FromBools creates a new bitvector with bits set to given values.
+// This method will return an error if the number of values is not 8.
+func NewBitvector8FromBools(bools []bool) (Bitvector8, error) {
+	if uint64(len(bools)) != bitvector8BitSize {
+		return nil, ErrWrongLen
+	}
+	return boolsToBytes(bools), nil
+}
+
+// NewBitvector8FromBigInt creates a new bitvector holding bits of a given integer, in the bit order of
+// BitAt. This method will return an error if the integer is negative or exceeds 8 bits.
+func NewBitvector8FromBigInt(x *big.Int) (Bitvector8, error) {
+	return bigIntToBytes(bitvector8BitSize, x)
+}
+
 // BitAt returns the bit value at the given index. If the index requested
 // exceeds the number of bits in the bitvector, then this method returns false.
 func (b Bitvector8) BitAt(idx uint64) bool {
@@ -95,11 +122,8 @@ func (b Bitvector8) BitIndices() []int {
 // Contains returns true if the bitlist contains all of the bits from the provided argument
 // bitlist. This method will return an error if bitlists are not the same length or not `bitvector8BitSize`.
 func (b Bitvector8) Contains(c Bitvector8) (bool, error) {
-	if b.Len() != c.Len() {
-		return false, ErrBitvectorDifferentLength
-	}
-	if b.Len() != bitvector8BitSize {
-		return false, ErrWrongLen
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return false, err
 	}
 
 	// Combine the byte from b and c, then XOR them against b. If the result of this is non-zero, then we
@@ -110,11 +134,8 @@ func (b Bitvector8) Contains(c Bitvector8) (bool, error) {
 // Overlaps returns true if the bitlist contains one of the bits from the provided argument
 // bitlist. This method will return an error if bitlists are not the same length.
 func (b Bitvector8) Overlaps(c Bitvector8) (bool, error) {
-	if b.Len() != c.Len() {
-		return false, ErrBitvectorDifferentLength
-	}
-	if b.Len() != bitvector8BitSize {
-		return false, ErrWrongLen
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return false, err
 	}
 
 	// Invert b and xor the byte from b and c, then and it against c. If the result is non-zero, then
@@ -125,12 +146,98 @@ func (b Bitvector8) Overlaps(c Bitvector8) (bool, error) {
 
 // Or returns the OR result of the two bitfields. This method will return an error if the bitlists are not the same length.
 func (b Bitvector8) Or(c Bitvector8) (Bitvector8, error) {
-	if b.Len() != c.Len() {
-		return nil, ErrBitvectorDifferentLength
-	}
-	if b.Len() != bitvector8BitSize {
-		return nil, ErrWrongLen
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return nil, err
 	}
 
 	return []byte{b[0] | c[0]}, nil
 }
+
+// Equal returns true if both bitvectors have the same bits set.
+func (b Bitvector8) Equal(c Bitvector8) bool {
+	return Equal(b, c)
+}
+
+// Compare returns an integer comparing two bitvectors lexicographically by bit index. The result
+// is 0 if b == c, -1 if b < c, and +1 if b > c.
+func (b Bitvector8) Compare(c Bitvector8) int {
+	return Compare(b, c)
+}
+
+// Hash returns a stable 64-bit hash of the bitvector. Equal bitfields have equal hashes,
+// regardless of their type.
+func (b Bitvector8) Hash() uint64 {
+	return Hash(b)
+}
+
+// HammingDistance returns the number of bits that differ between the two bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector8) HammingDistance(c Bitvector8) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return 0, err
+	}
+	return xorCountBits(b, c, bitvector8BitSize), nil
+}
+
+// IntersectionSize returns the number of bits set in both bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector8) IntersectionSize(c Bitvector8) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return 0, err
+	}
+	and, _ := andOrCountBits(b, c, bitvector8BitSize)
+	return and, nil
+}
+
+// UnionSize returns the number of bits set in either of the bitfields.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector8) UnionSize(c Bitvector8) (uint64, error) {
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return 0, err
+	}
+	_, or := andOrCountBits(b, c, bitvector8BitSize)
+	return or, nil
+}
+
+// Jaccard returns the Jaccard similarity of the two bitfields i.e. the size of the intersection
+// divided by the size of the union. The similarity of two bitfields with no bits set is 1.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector8) Jaccard(c Bitvector8) (float64, error) {
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return 0, err
+	}
+	return jaccard(andOrCountBits(b, c, bitvector8BitSize)), nil
+}
+
+// IsSubsetOf returns true if every bit set in the bitfield is also set in the provided argument.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector8) IsSubsetOf(c Bitvector8) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return false, err
+	}
+	return isSubsetBits(b, c, bitvector8BitSize), nil
+}
+
+// IsDisjoint returns true if the two bitfields have no bits set in common.
+// This method will return an error if the bitvectors are not the same length.
+func (b Bitvector8) IsDisjoint(c Bitvector8) (bool, error) {
+	if err := checkVectorLen(b, c, bitvector8ByteSize); err != nil {
+		return false, err
+	}
+	return isDisjointBits(b, c, bitvector8BitSize), nil
+}
+
+// ToBools returns bits of the bitvector as an array of booleans.
+func (b Bitvector8) ToBools() []bool {
+	return ToBools(b)
+}
+
+// ToBigInt returns bits of the bitvector as a non-negative integer, in the bit order of BitAt.
+func (b Bitvector8) ToBigInt() *big.Int {
+	return ToBigInt(b)
+}
+
+// Words returns bits of the bitvector as an array of words, in the layout used by Bitlist64.
+func (b Bitvector8) Words() []uint64 {
+	return bitfieldWords(b)
+}
@@ -449,3 +449,54 @@ func TestBitVector8_Or(t *testing.T) {
 		}
 	}
 }
+
+func TestBitvector8_FromBytes(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want Bitvector8
+		err  error
+	}{
+		{b: nil, err: ErrWrongLen},
+		{b: make([]byte, 1), want: NewBitvector8()},
+		{b: []byte{0xa5}, want: Bitvector8{0xa5}},
+		{b: make([]byte, 2), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		got, err := NewBitvector8FromBytes(tt.b)
+		if err != tt.err || !bytes.Equal(got, tt.want) {
+			t.Errorf("NewBitvector8FromBytes(%x) = %x, %v, wanted %x, %v", tt.b, got, err, tt.want, tt.err)
+		}
+		if err == nil && len(tt.b) > 0 {
+			tt.b[0] ^= 0x01
+			if bytes.Equal(got, tt.b) {
+				t.Errorf("NewBitvector8FromBytes(%x) does not copy the input", tt.b)
+			}
+		}
+	}
+}
+
+func TestBitvector8_WrongLen(t *testing.T) {
+	tests := []struct {
+		a   Bitvector8
+		b   Bitvector8
+		err error
+	}{
+		{a: NewBitvector8(), b: Bitvector8{}, err: ErrBitvectorDifferentLength},
+		{a: Bitvector8{}, b: NewBitvector8(), err: ErrBitvectorDifferentLength},
+		{a: Bitvector8{}, b: Bitvector8{}, err: ErrWrongLen},
+		{a: make(Bitvector8, 2), b: make(Bitvector8, 2), err: ErrWrongLen},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.a.Contains(tt.b); err != tt.err {
+			t.Errorf("(%x).Contains(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+		if _, err := tt.a.Overlaps(tt.b); err != tt.err {
+			t.Errorf("(%x).Overlaps(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+		if _, err := tt.a.Or(tt.b); err != tt.err {
+			t.Errorf("(%x).Or(%x) error = %v, wanted %v", tt.a, tt.b, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+//go:build go1.18
+// +build go1.18
+
+package bitfield
+
+import (
+	"bytes"
+	"testing"
+)
+
+// FuzzBitvector runs every operation of every bitvector type on arbitrary byte arrays, including
+// ones of the wrong length, making sure none of them panics and that length checks agree.
+func FuzzBitvector(f *testing.F) {
+	f.Add([]byte{0x01}, []byte{0x03}, uint64(0), 1)
+	f.Add([]byte{0x01, 0x23}, []byte{0xff}, uint64(15), -3)
+	f.Add(make([]byte, 16), make([]byte, 16), uint64(127), 100)
+	f.Add(make([]byte, 64), make([]byte, 8), uint64(511), -8)
+	f.Add([]byte{}, []byte{}, uint64(1)<<63, 1<<20)
+	f.Fuzz(func(t *testing.T, a, c []byte, idx uint64, shift int) {
+		fuzzBitvector4(t, a, c, idx, shift)
+		fuzzBitvector8(t, a, c, idx, shift)
+		fuzzBitvector16(t, a, c, idx, shift)
+		fuzzBitvector32(t, a, c, idx, shift)
+		fuzzBitvector64(t, a, c, idx, shift)
+		fuzzBitvector128(t, a, c, idx, shift)
+		fuzzBitvector256(t, a, c, idx, shift)
+		fuzzBitvector512(t, a, c, idx, shift)
+	})
+}
+
+// fuzzBitfield runs operations common to all bitfields, checking that results are consistent.
+func fuzzBitfield(t *testing.T, b Bitfield, idx uint64) {
+	if got := uint64(len(b.BitIndices())); got != b.Count() || got > b.Len() {
+		t.Fatalf("(%x).BitIndices() has %d bits, Count() = %d, Len() = %d", b, got, b.Count(), b.Len())
+	}
+	b.BitAt(idx)
+	b.Bytes()
+	ToBools(b)
+	ToBigInt(b)
+	Hash(b)
+	b.SetBitAt(idx, true)
+	b.SetBitAt(idx, false)
+}
+
+// checkFuzzErr makes sure that an operation on two bitvectors failed if, and only if, they are not
+// both backed by byte arrays of an expected size.
+func checkFuzzErr(t *testing.T, name string, err error, b, c []byte, byteSize int) {
+	if want := checkVectorLen(b, c, byteSize); err != want {
+		t.Fatalf("(%x).%s(%x) error = %v, wanted %v", b, name, c, err, want)
+	}
+}
+
+func fuzzBitvector4(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector4FromBytes(a)
+	if ok := len(a) == bitvector4ByteSize && a[0] < 0x10; (err == nil) != ok {
+		t.Fatalf("NewBitvector4FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector4FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector4(append([]byte(nil), a...)), Bitvector4(append([]byte(nil), c...))
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector4ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector4ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector4ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector4ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector4ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector4ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	b.AllSetInRange(idx%8, idx>>3%8)
+	b.Window(idx%8, idx>>3%8)
+	b.ShiftIn(idx%2 == 1)
+	b.Shift(shift)
+	fuzzBitfield(t, b, idx)
+}
+
+func fuzzBitvector8(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector8FromBytes(a)
+	if ok := len(a) == bitvector8ByteSize; (err == nil) != ok {
+		t.Fatalf("NewBitvector8FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector8FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector8(append([]byte(nil), a...)), Bitvector8(append([]byte(nil), c...))
+	_, err = b.Contains(d)
+	checkFuzzErr(t, "Contains", err, b, d, bitvector8ByteSize)
+	_, err = b.Overlaps(d)
+	checkFuzzErr(t, "Overlaps", err, b, d, bitvector8ByteSize)
+	_, err = b.Or(d)
+	checkFuzzErr(t, "Or", err, b, d, bitvector8ByteSize)
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector8ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector8ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector8ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector8ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector8ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector8ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	fuzzBitfield(t, b, idx)
+}
+
+func fuzzBitvector16(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector16FromBytes(a)
+	if ok := len(a) == bitvector16ByteSize; (err == nil) != ok {
+		t.Fatalf("NewBitvector16FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector16FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector16(append([]byte(nil), a...)), Bitvector16(append([]byte(nil), c...))
+	_, err = b.Contains(d)
+	checkFuzzErr(t, "Contains", err, b, d, bitvector16ByteSize)
+	_, err = b.Overlaps(d)
+	checkFuzzErr(t, "Overlaps", err, b, d, bitvector16ByteSize)
+	_, err = b.Or(d)
+	checkFuzzErr(t, "Or", err, b, d, bitvector16ByteSize)
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector16ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector16ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector16ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector16ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector16ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector16ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	b.Shift(shift)
+	fuzzBitfield(t, b, idx)
+}
+
+func fuzzBitvector32(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector32FromBytes(a)
+	if ok := len(a) == bitvector32ByteSize; (err == nil) != ok {
+		t.Fatalf("NewBitvector32FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector32FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector32(append([]byte(nil), a...)), Bitvector32(append([]byte(nil), c...))
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector32ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector32ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector32ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector32ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector32ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector32ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	fuzzBitfield(t, b, idx)
+}
+
+func fuzzBitvector64(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector64FromBytes(a)
+	if ok := len(a) == bitvector64ByteSize; (err == nil) != ok {
+		t.Fatalf("NewBitvector64FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector64FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector64(append([]byte(nil), a...)), Bitvector64(append([]byte(nil), c...))
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector64ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector64ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector64ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector64ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector64ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector64ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	b.Shift(shift)
+	fuzzBitfield(t, b, idx)
+}
+
+func fuzzBitvector128(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector128FromBytes(a)
+	if ok := len(a) == bitvector128ByteSize; (err == nil) != ok {
+		t.Fatalf("NewBitvector128FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector128FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector128(append([]byte(nil), a...)), Bitvector128(append([]byte(nil), c...))
+	_, err = b.Contains(d)
+	checkFuzzErr(t, "Contains", err, b, d, bitvector128ByteSize)
+	_, err = b.Overlaps(d)
+	checkFuzzErr(t, "Overlaps", err, b, d, bitvector128ByteSize)
+	_, err = b.Or(d)
+	checkFuzzErr(t, "Or", err, b, d, bitvector128ByteSize)
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector128ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector128ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector128ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector128ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector128ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector128ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	b.Shift(shift)
+	fuzzBitfield(t, b, idx)
+}
+
+func fuzzBitvector256(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector256FromBytes(a)
+	if ok := len(a) == bitvector256ByteSize; (err == nil) != ok {
+		t.Fatalf("NewBitvector256FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector256FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector256(append([]byte(nil), a...)), Bitvector256(append([]byte(nil), c...))
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector256ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector256ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector256ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector256ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector256ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector256ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	b.Shift(shift)
+	fuzzBitfield(t, b, idx)
+}
+
+func fuzzBitvector512(t *testing.T, a, c []byte, idx uint64, shift int) {
+	v, err := NewBitvector512FromBytes(a)
+	if ok := len(a) == bitvector512ByteSize; (err == nil) != ok {
+		t.Fatalf("NewBitvector512FromBytes(%x) error = %v", a, err)
+	}
+	if err == nil && !bytes.Equal(v, a) {
+		t.Fatalf("NewBitvector512FromBytes(%x) = %x", a, v)
+	}
+
+	b, d := Bitvector512(append([]byte(nil), a...)), Bitvector512(append([]byte(nil), c...))
+	_, err = b.HammingDistance(d)
+	checkFuzzErr(t, "HammingDistance", err, b, d, bitvector512ByteSize)
+	_, err = b.IntersectionSize(d)
+	checkFuzzErr(t, "IntersectionSize", err, b, d, bitvector512ByteSize)
+	_, err = b.UnionSize(d)
+	checkFuzzErr(t, "UnionSize", err, b, d, bitvector512ByteSize)
+	_, err = b.Jaccard(d)
+	checkFuzzErr(t, "Jaccard", err, b, d, bitvector512ByteSize)
+	_, err = b.IsSubsetOf(d)
+	checkFuzzErr(t, "IsSubsetOf", err, b, d, bitvector512ByteSize)
+	_, err = b.IsDisjoint(d)
+	checkFuzzErr(t, "IsDisjoint", err, b, d, bitvector512ByteSize)
+	b.Equal(d)
+	b.Compare(d)
+	b.Words()
+	b.Shift(shift)
+	fuzzBitfield(t, b, idx)
+}
@@ -0,0 +1,234 @@
+// Package bloom implements a Bloom filter on top of a Bitlist64 bit array.
+//
+// A Bloom filter is a probabilistic set, which answers whether an element was possibly added to
+// the set, or definitely was not. Every element is mapped to k bit positions in an array of m bits,
+// using double hashing over a 64-bit FNV-1a digest of the element. Filters with the same m and k
+// are compatible, and can be combined by OR-ing their bit arrays.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/theQRL/go-bitfield"
+)
+
+const (
+	// fnvOffset64 and fnvPrime64 are the parameters of the 64-bit FNV-1a hash.
+	fnvOffset64 = uint64(0xcbf29ce484222325)
+	fnvPrime64  = uint64(0x100000001b3)
+	// fixedSize is the SSZ size of the fixed part of the filter: k and an offset of the bit array.
+	fixedSize = 8 + 4
+)
+
+var (
+	ErrInvalidParameters  = errors.New("bloom filter requires m > 0 and 0 < k <= m")
+	ErrIncompatibleFilter = errors.New("bloom filters have different m or k")
+	ErrInvalidEncoding    = errors.New("invalid bloom filter encoding")
+)
+
+// Filter is a Bloom filter with k hash functions over m bits.
+type Filter struct {
+	k    uint64
+	bits *bitfield.Bitlist64
+}
+
+// New creates an empty filter with m bits and k hash functions. As every hash function sets at
+// most one bit, k may not exceed m.
+func New(m, k uint64) (*Filter, error) {
+	if m == 0 || k == 0 || k > m {
+		return nil, ErrInvalidParameters
+	}
+	return &Filter{
+		k:    k,
+		bits: bitfield.NewBitlist64(m),
+	}, nil
+}
+
+// NewWithEstimates creates an empty filter sized to hold n elements with a false positive rate
+// of at most fpRate.
+func NewWithEstimates(n uint64, fpRate float64) (*Filter, error) {
+	m, k := EstimateParameters(n, fpRate)
+	return New(m, k)
+}
+
+// EstimateParameters returns the number of bits m and the number of hash functions k required for
+// a filter holding n elements with a false positive rate of at most fpRate.
+func EstimateParameters(n uint64, fpRate float64) (m, k uint64) {
+	if n == 0 {
+		n = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 || math.IsNaN(fpRate) {
+		return 0, 0
+	}
+	// m = -n*ln(p) / ln(2)^2, k = m/n * ln(2).
+	m = uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k = uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k == 0 {
+		k = 1
+	}
+	return m, k
+}
+
+// M returns the number of bits in the filter.
+func (f *Filter) M() uint64 {
+	return f.bits.Len()
+}
+
+// K returns the number of hash functions used by the filter.
+func (f *Filter) K() uint64 {
+	return f.k
+}
+
+// Bits returns the underlying bit array of the filter. The bit array is not copied.
+func (f *Filter) Bits() *bitfield.Bitlist64 {
+	return f.bits
+}
+
+// Add adds a given element to the filter.
+func (f *Filter) Add(data []byte) {
+	h1, h2 := hashes(data)
+	m := f.bits.Len()
+	for i := uint64(0); i < f.k; i++ {
+		f.bits.SetBitAt((h1+i*h2)%m, true)
+	}
+}
+
+// Test returns true if a given element may have been added to the filter, and false if it
+// definitely was not.
+func (f *Filter) Test(data []byte) bool {
+	h1, h2 := hashes(data)
+	m := f.bits.Len()
+	for i := uint64(0); i < f.k; i++ {
+		if !f.bits.BitAt((h1 + i*h2) % m) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestAndAdd adds a given element to the filter, and returns the result Test would have returned
+// right before the element was added.
+func (f *Filter) TestAndAdd(data []byte) bool {
+	h1, h2 := hashes(data)
+	m := f.bits.Len()
+	present := true
+	for i := uint64(0); i < f.k; i++ {
+		idx := (h1 + i*h2) % m
+		if !f.bits.BitAt(idx) {
+			present = false
+			f.bits.SetBitAt(idx, true)
+		}
+	}
+	return present
+}
+
+// Union returns a new filter holding elements of both filters.
+// This method will return an error if the filters have different m or k.
+func (f *Filter) Union(g *Filter) (*Filter, error) {
+	if f.k != g.k || f.M() != g.M() {
+		return nil, ErrIncompatibleFilter
+	}
+	bits, err := f.bits.Or(g.bits)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{
+		k:    f.k,
+		bits: bits,
+	}, nil
+}
+
+// ApproximateCount estimates the number of distinct elements added to the filter, from the number
+// of bits set. If all bits are set, the filter is saturated and math.MaxUint64 is returned.
+func (f *Filter) ApproximateCount() uint64 {
+	m, x := float64(f.M()), float64(f.bits.Count())
+	if x >= m {
+		return math.MaxUint64
+	}
+	// n = -m/k * ln(1 - X/m).
+	return uint64(math.Round(-m / float64(f.k) * math.Log1p(-x/m)))
+}
+
+// FalsePositiveRate estimates the current false positive rate of the filter, from the number of
+// bits set.
+func (f *Filter) FalsePositiveRate() float64 {
+	return math.Pow(float64(f.bits.Count())/float64(f.M()), float64(f.k))
+}
+
+// SizeSSZ returns the size of the filter in SSZ encoding.
+func (f *Filter) SizeSSZ() int {
+	return fixedSize + int(f.M()/8+1)
+}
+
+// MarshalSSZ encodes the filter as an SSZ container of k (uint64) and the bit array (Bitlist).
+func (f *Filter) MarshalSSZ() ([]byte, error) {
+	return f.MarshalSSZTo(make([]byte, 0, f.SizeSSZ()))
+}
+
+// MarshalSSZTo appends SSZ encoding of the filter to a given buffer.
+func (f *Filter) MarshalSSZTo(buf []byte) ([]byte, error) {
+	var fixed [fixedSize]byte
+	binary.LittleEndian.PutUint64(fixed[:8], f.k)
+	binary.LittleEndian.PutUint32(fixed[8:], fixedSize)
+	buf = append(buf, fixed[:]...)
+	return append(buf, f.bits.ToBitlist()...), nil
+}
+
+// UnmarshalSSZ decodes the filter from its SSZ encoding. Parameters are validated as in New, so
+// that untrusted input cannot make Add and Test loop over a huge number of hash functions.
+func (f *Filter) UnmarshalSSZ(buf []byte) error {
+	if len(buf) < fixedSize+1 {
+		return ErrInvalidEncoding
+	}
+	k := binary.LittleEndian.Uint64(buf[:8])
+	if binary.LittleEndian.Uint32(buf[8:fixedSize]) != fixedSize {
+		return ErrInvalidEncoding
+	}
+	bl := bitfield.Bitlist(buf[fixedSize:])
+	if k == 0 || bl.Len() == 0 || k > bl.Len() {
+		return ErrInvalidEncoding
+	}
+	bits, err := bl.ToBitlist64()
+	if err != nil {
+		return ErrInvalidEncoding
+	}
+	f.k, f.bits = k, bits
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, using the SSZ encoding of the filter.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	return f.MarshalSSZ()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, using the SSZ encoding of the filter.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	return f.UnmarshalSSZ(data)
+}
+
+// hashes derives two hash values from a given element, used for double hashing: the i-th bit
+// position is (h1 + i*h2) mod m. The second hash is forced to be odd, so that it never degenerates
+// into zero.
+func hashes(data []byte) (uint64, uint64) {
+	h := fnvOffset64
+	for _, c := range data {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	// FNV leaves the low bits poorly mixed, which matters when reducing modulo m.
+	h1 := mix(h)
+	h2 := mix(h1 ^ fnvOffset64)
+	return h1, h2 | 1
+}
+
+// mix is the murmur3 64-bit finalizer, which makes every input bit affect every output bit.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
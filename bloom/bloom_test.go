@@ -0,0 +1,226 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func key(i uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], i)
+	return b[:]
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(0, 3); err != ErrInvalidParameters {
+		t.Errorf("New(0, 3) error = %v, wanted %v", err, ErrInvalidParameters)
+	}
+	if _, err := New(100, 0); err != ErrInvalidParameters {
+		t.Errorf("New(100, 0) error = %v, wanted %v", err, ErrInvalidParameters)
+	}
+	if _, err := New(100, 101); err != ErrInvalidParameters {
+		t.Errorf("New(100, 101) error = %v, wanted %v", err, ErrInvalidParameters)
+	}
+	f, err := New(100, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.M() != 100 || f.K() != 3 || f.Bits().Count() != 0 {
+		t.Errorf("New(100, 3) = m:%d, k:%d, count:%d", f.M(), f.K(), f.Bits().Count())
+	}
+}
+
+func TestEstimateParameters(t *testing.T) {
+	tests := []struct {
+		n      uint64
+		fpRate float64
+		m, k   uint64
+	}{
+		{n: 1000, fpRate: 0.01, m: 9586, k: 7},
+		{n: 1000, fpRate: 0.001, m: 14378, k: 10},
+		{n: 0, fpRate: 0.5, m: 2, k: 1},
+		{n: 10, fpRate: 0, m: 0, k: 0},
+		{n: 10, fpRate: 1, m: 0, k: 0},
+	}
+
+	for _, tt := range tests {
+		m, k := EstimateParameters(tt.n, tt.fpRate)
+		if m != tt.m || k != tt.k {
+			t.Errorf("EstimateParameters(%d, %v) = %d, %d, wanted %d, %d", tt.n, tt.fpRate, m, k, tt.m, tt.k)
+		}
+	}
+
+	if _, err := NewWithEstimates(10, 0); err != ErrInvalidParameters {
+		t.Errorf("NewWithEstimates(10, 0) error = %v, wanted %v", err, ErrInvalidParameters)
+	}
+}
+
+func TestFilter_AddTest(t *testing.T) {
+	const n = 10000
+	f, err := NewWithEstimates(n, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < n; i++ {
+		f.Add(key(i))
+	}
+	// No false negatives.
+	for i := uint64(0); i < n; i++ {
+		if !f.Test(key(i)) {
+			t.Fatalf("Test(%d) = false, wanted true", i)
+		}
+	}
+	// False positive rate close to the target.
+	var fp int
+	for i := uint64(n); i < 11*n; i++ {
+		if f.Test(key(i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / (10 * n); rate > 0.015 {
+		t.Errorf("False positive rate = %v, wanted at most 0.015", rate)
+	}
+	if rate := f.FalsePositiveRate(); rate > 0.015 {
+		t.Errorf("FalsePositiveRate() = %v, wanted at most 0.015", rate)
+	}
+}
+
+func TestFilter_TestAndAdd(t *testing.T) {
+	f, err := New(1024, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.TestAndAdd([]byte("attestation")) {
+		t.Error("TestAndAdd() = true for a new element")
+	}
+	if !f.TestAndAdd([]byte("attestation")) {
+		t.Error("TestAndAdd() = false for an added element")
+	}
+	if !f.Test([]byte("attestation")) {
+		t.Error("Test() = false for an added element")
+	}
+}
+
+func TestFilter_Union(t *testing.T) {
+	f, _ := New(2048, 5)
+	g, _ := New(2048, 5)
+	for i := uint64(0); i < 100; i++ {
+		f.Add(key(i))
+		g.Add(key(i + 100))
+	}
+	u, err := f.Union(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 200; i++ {
+		if !u.Test(key(i)) {
+			t.Errorf("Union().Test(%d) = false, wanted true", i)
+		}
+	}
+	// Sources are not modified.
+	if f.Test(key(150)) && f.Test(key(151)) && f.Test(key(152)) {
+		t.Error("Union() modified the source filter")
+	}
+
+	h, _ := New(2048, 4)
+	if _, err := f.Union(h); err != ErrIncompatibleFilter {
+		t.Errorf("Union() error = %v, wanted %v", err, ErrIncompatibleFilter)
+	}
+	h, _ = New(2047, 5)
+	if _, err := f.Union(h); err != ErrIncompatibleFilter {
+		t.Errorf("Union() error = %v, wanted %v", err, ErrIncompatibleFilter)
+	}
+}
+
+func TestFilter_ApproximateCount(t *testing.T) {
+	f, _ := NewWithEstimates(5000, 0.01)
+	if got := f.ApproximateCount(); got != 0 {
+		t.Errorf("ApproximateCount() = %d, wanted 0", got)
+	}
+	for i := uint64(0); i < 3000; i++ {
+		f.Add(key(i))
+	}
+	if got := f.ApproximateCount(); math.Abs(float64(got)-3000) > 3000*0.05 {
+		t.Errorf("ApproximateCount() = %d, wanted 3000 +/- 5%%", got)
+	}
+
+	full, _ := New(8, 1)
+	for i := uint64(0); i < 8; i++ {
+		full.Bits().SetBitAt(i, true)
+	}
+	if got := full.ApproximateCount(); got != math.MaxUint64 {
+		t.Errorf("ApproximateCount() = %d, wanted %d", got, uint64(math.MaxUint64))
+	}
+}
+
+func TestFilter_MarshalSSZ(t *testing.T) {
+	for _, m := range []uint64{1, 7, 8, 9, 64, 1000} {
+		k := uint64(3)
+		if m < k {
+			k = m
+		}
+		f, err := New(m, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := uint64(0); i < m/4; i++ {
+			f.Add(key(i))
+		}
+		enc, err := f.MarshalSSZ()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(enc) != f.SizeSSZ() {
+			t.Errorf("len(MarshalSSZ()) = %d, wanted %d", len(enc), f.SizeSSZ())
+		}
+		dec := &Filter{}
+		if err := dec.UnmarshalSSZ(enc); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dec, f) {
+			t.Errorf("UnmarshalSSZ(MarshalSSZ()) = %+v, wanted %+v", dec, f)
+		}
+
+		bin, err := f.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec = &Filter{}
+		if err := dec.UnmarshalBinary(bin); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dec, f) {
+			t.Errorf("UnmarshalBinary(MarshalBinary()) = %+v, wanted %+v", dec, f)
+		}
+	}
+
+	t.Run("layout", func(t *testing.T) {
+		f, _ := New(4, 2)
+		f.Bits().SetBitAt(1, true)
+		enc, _ := f.MarshalSSZ()
+		want := []byte{0x02, 0, 0, 0, 0, 0, 0, 0, 0x0C, 0, 0, 0, 0x12}
+		if !reflect.DeepEqual(enc, want) {
+			t.Errorf("MarshalSSZ() = %x, wanted %x", enc, want)
+		}
+	})
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		tests := [][]byte{
+			nil,
+			{0x02, 0, 0, 0, 0, 0, 0, 0, 0x0C, 0, 0, 0},       // Missing bit array.
+			{0x00, 0, 0, 0, 0, 0, 0, 0, 0x0C, 0, 0, 0, 0x12}, // k = 0.
+			{0x05, 0, 0, 0, 0, 0, 0, 0, 0x0C, 0, 0, 0, 0x12}, // k > m.
+			{0, 0, 0, 0, 0, 0, 0, 0x80, 0x0C, 0, 0, 0, 0x12}, // k = 2^63.
+			{0x02, 0, 0, 0, 0, 0, 0, 0, 0x0D, 0, 0, 0, 0x12}, // Wrong offset.
+			{0x02, 0, 0, 0, 0, 0, 0, 0, 0x0C, 0, 0, 0, 0x01}, // m = 0.
+			{0x02, 0, 0, 0, 0, 0, 0, 0, 0x0C, 0, 0, 0, 0x00}, // No length bit.
+		}
+		for _, enc := range tests {
+			if err := (&Filter{}).UnmarshalSSZ(enc); err != ErrInvalidEncoding {
+				t.Errorf("UnmarshalSSZ(%x) error = %v, wanted %v", enc, err, ErrInvalidEncoding)
+			}
+		}
+	})
+}
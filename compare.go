@@ -0,0 +1,218 @@
+package bitfield
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// hashSeed is the initial state of the bitfield hash, chosen as the FNV-1a 64-bit offset basis.
+	hashSeed = uint64(0xcbf29ce484222325)
+	// hashPrime is the multiplier used when folding a word into the hash state.
+	hashPrime = uint64(0x100000001b3)
+)
+
+// Equal returns true if both bitfields have the same length and the same bits set. The bitfields
+// do not need to be of the same type i.e. a Bitlist and a Bitlist64 holding the same bits are equal.
+func Equal(a, b Bitfield) bool {
+	if x, ok := a.(*Bitlist64); ok {
+		if y, ok := b.(*Bitlist64); ok {
+			return x.Equal(y)
+		}
+	}
+	aBytes, aLen := bitBytes(a)
+	bBytes, bLen := bitBytes(b)
+	return equalBits(aBytes, aLen, bBytes, bLen)
+}
+
+// Compare returns an integer comparing two bitfields lexicographically by bit index. The first
+// differing bit decides the order, with an unset bit ordering before a set one. If one bitfield is
+// a prefix of the other, the shorter one orders first. The result is 0 if a == b, -1 if a < b,
+// and +1 if a > b, which makes it suitable for sorting.
+func Compare(a, b Bitfield) int {
+	if x, ok := a.(*Bitlist64); ok {
+		if y, ok := b.(*Bitlist64); ok {
+			return x.Compare(y)
+		}
+	}
+	aBytes, aLen := bitBytes(a)
+	bBytes, bLen := bitBytes(b)
+	return compareBits(aBytes, aLen, bBytes, bLen)
+}
+
+// Hash returns a stable 64-bit hash of the bitfield, derived from its length and bits only.
+// Bitfields that are Equal have the same hash regardless of their type, and the hash does not
+// change between runs, so it can be used as a map key or persisted for deduplication.
+func Hash(b Bitfield) uint64 {
+	if x, ok := b.(*Bitlist64); ok {
+		return x.Hash()
+	}
+	data, n := bitBytes(b)
+	return hashBits(data, n)
+}
+
+// bitBytes returns little-endian bytes holding the bits of a given bitfield, alongside its length.
+// The returned slice holds at least (n+7)/8 bytes. Bits at or above n may be set (e.g. the length
+// bit of a Bitlist), so callers must mask them out.
+func bitBytes(b Bitfield) ([]byte, uint64) {
+	switch v := b.(type) {
+	case Bitlist:
+		return v, v.Len()
+	case *Bitlist64:
+		ret := make([]byte, len(v.data)*bytesInWord)
+		for idx, word := range v.data {
+			start := idx << bytesInWordLog2
+			binary.LittleEndian.PutUint64(ret[start:start+bytesInWord], word)
+		}
+		return ret, v.size
+	default:
+		n := b.Len()
+		ret := b.Bytes()
+		if numBytes := int((n + 7) >> 3); len(ret) < numBytes {
+			ret = append(ret, make([]byte, numBytes-len(ret))...)
+		}
+		return ret, n
+	}
+}
+
+// bitWords returns little-endian words holding the bits of a given bitfield, alongside its length.
+// The returned slice holds at least numWordsRequired(n) words. Bits at or above n may be set, so
+// callers must mask them out.
+func bitWords(b Bitfield) ([]uint64, uint64) {
+	if v, ok := b.(*Bitlist64); ok {
+		return v.data, v.size
+	}
+	data, n := bitBytes(b)
+	ret := make([]uint64, numWordsRequired(n))
+	for idx := range ret {
+		var buf [bytesInWord]byte
+		copy(buf[:], data[idx<<bytesInWordLog2:])
+		ret[idx] = binary.LittleEndian.Uint64(buf[:])
+	}
+	return ret, n
+}
+
+// lastByteMask returns the mask of bits in use in the last byte of an n bit bitfield.
+func lastByteMask(n uint64) byte {
+	if n%8 == 0 {
+		return 0xff
+	}
+	return byte(0xff >> (8 - n%8))
+}
+
+// lastWordMask returns the mask of bits in use in the last word of an n bit bitfield.
+func lastWordMask(n uint64) uint64 {
+	if n%wordSize == 0 {
+		return allBitsSet
+	}
+	return allBitsSet >> (wordSize - n%wordSize)
+}
+
+// equalBits compares n bits of two byte arrays, ignoring any bits at or above the length.
+func equalBits(a []byte, aLen uint64, b []byte, bLen uint64) bool {
+	return aLen == bLen && compareBits(a, aLen, b, bLen) == 0
+}
+
+// compareBits orders two byte arrays lexicographically by bit index, see Compare.
+func compareBits(a []byte, aLen uint64, b []byte, bLen uint64) int {
+	n := aLen
+	if bLen < n {
+		n = bLen
+	}
+	numBytes := int((n + 7) >> 3)
+	for i := 0; i < numBytes; i++ {
+		diff := a[i] ^ b[i]
+		if i == numBytes-1 {
+			diff &= lastByteMask(n)
+		}
+		if diff == 0 {
+			continue
+		}
+		// The lowest differing bit is the first one in bit index order.
+		bit := diff & -diff
+		if a[i]&bit == 0 {
+			return -1
+		}
+		return 1
+	}
+	return compareLen(aLen, bLen)
+}
+
+// compareWords orders two word arrays lexicographically by bit index, see Compare.
+func compareWords(a []uint64, aLen uint64, b []uint64, bLen uint64) int {
+	n := aLen
+	if bLen < n {
+		n = bLen
+	}
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords; i++ {
+		diff := a[i] ^ b[i]
+		if i == numWords-1 {
+			diff &= lastWordMask(n)
+		}
+		if diff == 0 {
+			continue
+		}
+		if a[i]&(1<<uint(bits.TrailingZeros64(diff))) == 0 {
+			return -1
+		}
+		return 1
+	}
+	return compareLen(aLen, bLen)
+}
+
+func compareLen(aLen, bLen uint64) int {
+	switch {
+	case aLen < bLen:
+		return -1
+	case aLen > bLen:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// hashBits hashes n bits of a byte array. Bytes are folded into the hash as little-endian words,
+// so that the result matches hashWords for the same bits.
+func hashBits(b []byte, n uint64) uint64 {
+	h := hashSeed ^ n
+	numBytes := int((n + 7) >> 3)
+	for start := 0; start < numBytes; start += bytesInWord {
+		var word uint64
+		end := min(start+bytesInWord, numBytes)
+		for i := end - 1; i >= start; i-- {
+			word = word<<8 | uint64(b[i])
+		}
+		if end == numBytes {
+			word &= lastWordMask(n)
+		}
+		h = hashMix(h ^ word)
+	}
+	return hashMix(h)
+}
+
+// hashWords hashes n bits of a word array, see hashBits.
+func hashWords(data []uint64, n uint64) uint64 {
+	h := hashSeed ^ n
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords; i++ {
+		word := data[i]
+		if i == numWords-1 {
+			word &= lastWordMask(n)
+		}
+		h = hashMix(h ^ word)
+	}
+	return hashMix(h)
+}
+
+// hashMix multiplies the state by a prime and then avalanches it, so that every input bit affects
+// every output bit.
+func hashMix(h uint64) uint64 {
+	h *= hashPrime
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
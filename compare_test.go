@@ -0,0 +1,314 @@
+package bitfield
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	bl64 := func(n uint64, idx ...uint64) *Bitlist64 {
+		b := NewBitlist64(n)
+		for _, i := range idx {
+			b.SetBitAt(i, true)
+		}
+		return b
+	}
+	tests := []struct {
+		a, b Bitfield
+		want bool
+	}{
+		{
+			a:    Bitlist{0x01},
+			b:    NewBitlist64(0),
+			want: true,
+		},
+		{
+			a:    Bitlist{0x1D}, // 0b00011101
+			b:    bl64(4, 0, 2, 3),
+			want: true,
+		},
+		{
+			a:    Bitlist{0x1D}, // 0b00011101
+			b:    bl64(4, 0, 2),
+			want: false,
+		},
+		{
+			a:    Bitlist{0x1D}, // 0b00011101
+			b:    bl64(5, 0, 2, 3),
+			want: false,
+		},
+		{
+			a:    Bitlist{0x05, 0x01},
+			b:    Bitvector8{0x05},
+			want: true,
+		},
+		{
+			a:    Bitvector4{0xF5}, // Bits above 4 are ignored.
+			b:    Bitlist{0x15},
+			want: true,
+		},
+		{
+			a:    Bitvector64{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80},
+			b:    bl64(64, 0, 63),
+			want: true,
+		},
+		{
+			a:    Bitvector128{0x01},
+			b:    bl64(128, 0),
+			want: true,
+		},
+		{
+			a:    Bitvector128{0x01},
+			b:    bl64(128, 1),
+			want: false,
+		},
+		{
+			a:    bl64(100, 3, 99),
+			b:    bl64(100, 3, 99),
+			want: true,
+		},
+		{
+			a:    bl64(100, 3, 99),
+			b:    bl64(100, 3, 98),
+			want: false,
+		},
+		{
+			a:    &Bitlist64{size: 4, data: []uint64{0xF5}}, // Bits above 4 are ignored.
+			b:    bl64(4, 0, 2),
+			want: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if got := Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(%v, %v) = %t, wanted %t", tt.a, tt.b, got, tt.want)
+			}
+			if got := Equal(tt.b, tt.a); got != tt.want {
+				t.Errorf("Equal(%v, %v) = %t, wanted %t", tt.b, tt.a, got, tt.want)
+			}
+			if tt.want && Hash(tt.a) != Hash(tt.b) {
+				t.Errorf("Hash(%v) = %x, Hash(%v) = %x, wanted equal hashes", tt.a, Hash(tt.a), tt.b, Hash(tt.b))
+			}
+			if tt.want && Compare(tt.a, tt.b) != 0 {
+				t.Errorf("Compare(%v, %v) = %d, wanted 0", tt.a, tt.b, Compare(tt.a, tt.b))
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b Bitfield
+		want int
+	}{
+		{
+			a:    Bitlist{0x01},
+			b:    Bitlist{0x01},
+			want: 0,
+		},
+		{
+			a:    Bitlist{0x01},
+			b:    Bitlist{0x02},
+			want: -1,
+		},
+		{
+			a:    Bitlist{0x12}, // bits=[0,1,0,0]
+			b:    Bitlist{0x11}, // bits=[1,0,0,0]
+			want: -1,
+		},
+		{
+			a:    Bitlist{0x13}, // bits=[1,1,0,0]
+			b:    Bitlist{0x11}, // bits=[1,0,0,0]
+			want: 1,
+		},
+		{
+			a:    Bitlist{0x09}, // bits=[1,0,0]
+			b:    Bitlist{0x11}, // bits=[1,0,0,0]
+			want: -1,
+		},
+		{
+			a:    Bitlist{0x0B}, // bits=[1,1,0]
+			b:    Bitlist{0x11}, // bits=[1,0,0,0]
+			want: 1,
+		},
+		{
+			a:    NewBitlist64From([]uint64{0x00, 0x01}),
+			b:    NewBitlist64From([]uint64{0x00, 0x02}),
+			want: 1,
+		},
+		{
+			a:    NewBitlist64From([]uint64{0x00, 0x01}),
+			b:    Bitvector128{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			want: 0,
+		},
+		{
+			a:    Bitvector16{0x00, 0x01},
+			b:    Bitvector16{0x00, 0x80},
+			want: 1,
+		},
+		{
+			a:    Bitvector4{0x04},
+			b:    Bitlist{0x1C}, // bits=[0,0,1,1]
+			want: -1,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare(%v, %v) = %d, wanted %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := Compare(tt.b, tt.a); got != -tt.want {
+				t.Errorf("Compare(%v, %v) = %d, wanted %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+
+	t.Run("sort", func(t *testing.T) {
+		bls := []Bitlist{{0x13}, {0x01}, {0x0B}, {0x11}, {0x12}, {0x09}}
+		sort.Slice(bls, func(i, j int) bool {
+			return bls[i].Compare(bls[j]) < 0
+		})
+		want := []Bitlist{{0x01}, {0x12}, {0x09}, {0x11}, {0x0B}, {0x13}}
+		for i := range want {
+			if !bls[i].Equal(want[i]) {
+				t.Fatalf("Sorted bitlists = %x, wanted %x", bls, want)
+			}
+		}
+	})
+}
+
+func TestHash(t *testing.T) {
+	t.Run("stable", func(t *testing.T) {
+		// The hash must not change between runs, as it may be persisted.
+		want := Hash(Bitlist{0x1D})
+		for i := 0; i < 10; i++ {
+			if got := Hash(Bitlist{0x1D}); got != want {
+				t.Errorf("Hash() = %x, wanted %x", got, want)
+			}
+		}
+	})
+	t.Run("length is part of the hash", func(t *testing.T) {
+		if Hash(NewBitlist64(10)) == Hash(NewBitlist64(11)) {
+			t.Error("Empty bitlists of different lengths have equal hashes")
+		}
+		if Hash(NewBitvector64()) == Hash(NewBitvector128()) {
+			t.Error("Empty bitvectors of different lengths have equal hashes")
+		}
+	})
+	t.Run("distinct", func(t *testing.T) {
+		seen := make(map[uint64]int)
+		for i := 0; i < 256; i++ {
+			b := NewBitlist64(256)
+			b.SetBitAt(uint64(i), true)
+			h := b.Hash()
+			if j, ok := seen[h]; ok {
+				t.Errorf("Bitlists with bits %d and %d set have equal hashes", i, j)
+			}
+			seen[h] = i
+		}
+	})
+	t.Run("cross type", func(t *testing.T) {
+		for n := uint64(0); n < 200; n++ {
+			b := NewBitlist(n)
+			for i := uint64(0); i < n; i += 3 {
+				b.SetBitAt(i, true)
+			}
+			b64, err := b.ToBitlist64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.Hash() != b64.Hash() {
+				t.Errorf("size:%d Bitlist.Hash() = %x, Bitlist64.Hash() = %x", n, b.Hash(), b64.Hash())
+			}
+		}
+	})
+}
+
+func TestBitvector_EqualCompareHash(t *testing.T) {
+	tests := []struct {
+		equal func() bool
+		cmp   func() int
+		hash  func() (uint64, uint64)
+		want  int
+	}{
+		{
+			equal: func() bool { return Bitvector4{0x13}.Equal(Bitvector4{0x03}) },
+			cmp:   func() int { return Bitvector4{0x13}.Compare(Bitvector4{0x03}) },
+			hash:  func() (uint64, uint64) { return Bitvector4{0x13}.Hash(), Bitvector4{0x03}.Hash() },
+			want:  0,
+		},
+		{
+			equal: func() bool { return Bitvector8{0x01}.Equal(Bitvector8{0x02}) },
+			cmp:   func() int { return Bitvector8{0x01}.Compare(Bitvector8{0x02}) },
+			hash:  func() (uint64, uint64) { return Bitvector8{0x01}.Hash(), Bitvector8{0x02}.Hash() },
+			want:  1,
+		},
+		{
+			equal: func() bool { return Bitvector16{0x00, 0x01}.Equal(Bitvector16{0x00, 0x01}) },
+			cmp:   func() int { return Bitvector16{0x00, 0x01}.Compare(Bitvector16{0x00, 0x01}) },
+			hash:  func() (uint64, uint64) { return Bitvector16{0x00, 0x01}.Hash(), Bitvector16{0x00, 0x01}.Hash() },
+			want:  0,
+		},
+		{
+			equal: func() bool { return Bitvector32{0x00, 0x02, 0x00, 0x00}.Equal(Bitvector32{0x00, 0x01, 0x00, 0x00}) },
+			cmp:   func() int { return Bitvector32{0x00, 0x02, 0x00, 0x00}.Compare(Bitvector32{0x00, 0x01, 0x00, 0x00}) },
+			hash: func() (uint64, uint64) {
+				return Bitvector32{0x00, 0x02, 0x00, 0x00}.Hash(), Bitvector32{0x00, 0x01, 0x00, 0x00}.Hash()
+			},
+			want: -1,
+		},
+		{
+			equal: func() bool { return NewBitvector64().Equal(NewBitvector64()) },
+			cmp:   func() int { return NewBitvector64().Compare(NewBitvector64()) },
+			hash:  func() (uint64, uint64) { return NewBitvector64().Hash(), NewBitvector64().Hash() },
+			want:  0,
+		},
+		{
+			equal: func() bool { return NewBitvector128().Equal(Bitvector128{0x00, 0x80}) },
+			cmp:   func() int { return NewBitvector128().Compare(Bitvector128{0x00, 0x80}) },
+			hash:  func() (uint64, uint64) { return NewBitvector128().Hash(), Bitvector128{0x00, 0x80}.Hash() },
+			want:  -1,
+		},
+		{
+			equal: func() bool { return NewBitvector256().Equal(NewBitvector256()) },
+			cmp:   func() int { return NewBitvector256().Compare(NewBitvector256()) },
+			hash:  func() (uint64, uint64) { return NewBitvector256().Hash(), NewBitvector256().Hash() },
+			want:  0,
+		},
+		{
+			equal: func() bool {
+				a, b := NewBitvector512(), NewBitvector512()
+				a.SetBitAt(511, true)
+				return a.Equal(b)
+			},
+			cmp: func() int {
+				a, b := NewBitvector512(), NewBitvector512()
+				a.SetBitAt(511, true)
+				return a.Compare(b)
+			},
+			hash: func() (uint64, uint64) {
+				a, b := NewBitvector512(), NewBitvector512()
+				a.SetBitAt(511, true)
+				return a.Hash(), b.Hash()
+			},
+			want: 1,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if got := tt.equal(); got != (tt.want == 0) {
+				t.Errorf("Equal() = %t, wanted %t", got, tt.want == 0)
+			}
+			if got := tt.cmp(); got != tt.want {
+				t.Errorf("Compare() = %d, wanted %d", got, tt.want)
+			}
+			if h1, h2 := tt.hash(); (h1 == h2) != (tt.want == 0) {
+				t.Errorf("Hash() = %x, %x, wanted equal: %t", h1, h2, tt.want == 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,413 @@
+package bitfield
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"math/bits"
+)
+
+const (
+	// containerMagic starts every bitfield container.
+	containerMagic = "BTFD"
+	// containerVersion is the version of the container layout written by Encode.
+	containerVersion = 1
+	// containerHeaderSize is the size of the container header: magic, version, type, encoding, a
+	// reserved byte and the bit length.
+	containerHeaderSize = 16
+	// containerChecksumSize is the size of the CRC-32C checksum ending every container.
+	containerChecksumSize = 4
+	// maxContainerLen is the largest bit length of a decoded container. As a sparse payload can
+	// describe a huge bitfield in a few bytes, it bounds the memory allocated when decoding, and
+	// makes sure the number of words always fits into an int.
+	maxContainerLen = 1 << 32
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// BitfieldType identifies the type of a bitfield stored in a container.
+type BitfieldType uint8
+
+// Types of bitfields that can be stored in a container.
+const (
+	TypeBitlist BitfieldType = iota + 1
+	TypeBitlist64
+	TypePersistentBitlist
+	TypeBitvector4
+	TypeBitvector8
+	TypeBitvector16
+	TypeBitvector32
+	TypeBitvector64
+	TypeBitvector128
+	TypeBitvector256
+	TypeBitvector512
+	TypeSparseBitlist
+)
+
+// bitvectorSizes holds the number of bits of every bitvector type.
+var bitvectorSizes = map[BitfieldType]uint64{
+	TypeBitvector4:   bitvector4BitSize,
+	TypeBitvector8:   bitvector8BitSize,
+	TypeBitvector16:  bitvector16BitSize,
+	TypeBitvector32:  bitvector32BitSize,
+	TypeBitvector64:  bitvector64BitSize,
+	TypeBitvector128: bitvector128BitSize,
+	TypeBitvector256: bitvector256BitSize,
+	TypeBitvector512: bitvector512BitSize,
+}
+
+// PayloadEncoding identifies how bits of a bitfield are laid out in a container.
+type PayloadEncoding uint8
+
+// Payload encodings of a container.
+const (
+	// EncodingRaw stores bits packed into bytes, least significant bit first.
+	EncodingRaw PayloadEncoding = iota
+	// EncodingRLE stores lengths of alternating runs of unset and set bits as uvarints, starting
+	// with a (possibly empty) run of unset bits.
+	EncodingRLE
+	// EncodingSparse stores the number of set bits, followed by gaps between indices of set bits
+	// as uvarints.
+	EncodingSparse
+)
+
+// ContainerHeader describes a bitfield stored in a container.
+type ContainerHeader struct {
+	Version  uint8
+	Type     BitfieldType
+	Encoding PayloadEncoding
+	Len      uint64
+}
+
+// Encode stores a bitfield in a self-describing container, using whichever payload encoding is
+// the smallest.
+// This method will return an error if the bitfield type is not supported.
+func Encode(b Bitfield) ([]byte, error) {
+	var ret []byte
+	for _, enc := range []PayloadEncoding{EncodingRaw, EncodingRLE, EncodingSparse} {
+		data, err := EncodeWith(b, enc)
+		if err != nil {
+			return nil, err
+		}
+		if ret == nil || len(data) < len(ret) {
+			ret = data
+		}
+	}
+	return ret, nil
+}
+
+// EncodeWith stores a bitfield in a self-describing container, using a given payload encoding.
+// The container starts with a 16 byte header: the magic "BTFD", the version, the type, the payload
+// encoding, a reserved zero byte and the bit length as a little-endian uint64. The header is
+// followed by the payload, and the little-endian CRC-32C checksum of all preceding bytes.
+// This method will return an error if the bitfield type or the encoding is not supported.
+func EncodeWith(b Bitfield, enc PayloadEncoding) ([]byte, error) {
+	t, err := typeOf(b)
+	if err != nil {
+		return nil, err
+	}
+	data, n := bitWords(b)
+
+	buf := make([]byte, containerHeaderSize, containerHeaderSize+int((n+7)>>3)+containerChecksumSize)
+	copy(buf, containerMagic)
+	buf[4] = containerVersion
+	buf[5] = byte(t)
+	buf[6] = byte(enc)
+	binary.LittleEndian.PutUint64(buf[8:], n)
+
+	switch enc {
+	case EncodingRaw:
+		buf = appendRaw(buf, data, n)
+	case EncodingRLE:
+		buf = appendRLE(buf, data, n)
+	case EncodingSparse:
+		buf = appendSparse(buf, data, n)
+	default:
+		return nil, ErrUnknownEncoding
+	}
+
+	var checksum [containerChecksumSize]byte
+	binary.LittleEndian.PutUint32(checksum[:], crc32.Checksum(buf, castagnoliTable))
+	return append(buf, checksum[:]...), nil
+}
+
+// DecodeHeader validates a container and returns its header, without decoding the bitfield. The
+// length in the header is checked against the payload, and may not exceed 2^32 bits.
+// This method will return an error if the container is malformed or corrupted.
+func DecodeHeader(data []byte) (ContainerHeader, error) {
+	if len(data) < len(containerMagic) || string(data[:len(containerMagic)]) != containerMagic {
+		return ContainerHeader{}, ErrInvalidMagic
+	}
+	if len(data) < containerHeaderSize+containerChecksumSize {
+		return ContainerHeader{}, ErrTruncated
+	}
+	checksum := binary.LittleEndian.Uint32(data[len(data)-containerChecksumSize:])
+	if crc32.Checksum(data[:len(data)-containerChecksumSize], castagnoliTable) != checksum {
+		return ContainerHeader{}, ErrInvalidChecksum
+	}
+
+	h := ContainerHeader{
+		Version:  data[4],
+		Type:     BitfieldType(data[5]),
+		Encoding: PayloadEncoding(data[6]),
+		Len:      binary.LittleEndian.Uint64(data[8:]),
+	}
+	if h.Version != containerVersion || data[7] != 0 {
+		return ContainerHeader{}, ErrUnsupportedVersion
+	}
+	if h.Type < TypeBitlist || h.Type > TypeSparseBitlist {
+		return ContainerHeader{}, ErrUnknownType
+	}
+	if h.Encoding > EncodingSparse {
+		return ContainerHeader{}, ErrUnknownEncoding
+	}
+	if size, ok := bitvectorSizes[h.Type]; ok && h.Len != size {
+		return ContainerHeader{}, ErrInvalidPayload
+	}
+	if !payloadDescribes(h.Encoding, data[containerHeaderSize:len(data)-containerChecksumSize], h.Len) {
+		return ContainerHeader{}, ErrInvalidPayload
+	}
+	return h, nil
+}
+
+// payloadDescribes returns true if a payload of a given encoding can describe a bitfield of n bits,
+// without allocating it. Raw payloads hold exactly the bytes needed, and runs of an RLE payload
+// add up to the length. Sparse payloads may end with any number of unset bits, so only the
+// maximum length applies to them.
+func payloadDescribes(enc PayloadEncoding, payload []byte, n uint64) bool {
+	if n > maxContainerLen {
+		return false
+	}
+	switch enc {
+	case EncodingRaw:
+		return uint64(len(payload)) == (n+7)>>3
+	case EncodingRLE:
+		var pos uint64
+		for len(payload) > 0 {
+			run, m := binary.Uvarint(payload)
+			if m <= 0 || run > n-pos {
+				return false
+			}
+			payload = payload[m:]
+			pos += run
+		}
+		return pos == n
+	default:
+		return true
+	}
+}
+
+// Decode returns the bitfield stored in a container, of the type it was encoded from. The type
+// and the payload encoding are detected from the container header.
+// This method will return an error if the container is malformed or corrupted.
+func Decode(data []byte) (Bitfield, error) {
+	h, err := DecodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	// Cap the payload, so that appending to it never overwrites the checksum.
+	payload := data[containerHeaderSize : len(data)-containerChecksumSize : len(data)-containerChecksumSize]
+
+	var b *Bitlist64
+	switch h.Encoding {
+	case EncodingRaw:
+		b, err = decodeRaw(payload, h.Len)
+	case EncodingRLE:
+		b, err = decodeRLE(payload, h.Len)
+	default:
+		b, err = decodeSparse(payload, h.Len)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromBitlist64(h.Type, b), nil
+}
+
+// typeOf returns the container type of a given bitfield.
+func typeOf(b Bitfield) (BitfieldType, error) {
+	switch b.(type) {
+	case Bitlist:
+		return TypeBitlist, nil
+	case *Bitlist64:
+		return TypeBitlist64, nil
+	case *PersistentBitlist:
+		return TypePersistentBitlist, nil
+	case *SparseBitlist:
+		return TypeSparseBitlist, nil
+	case Bitvector4:
+		return TypeBitvector4, nil
+	case Bitvector8:
+		return TypeBitvector8, nil
+	case Bitvector16:
+		return TypeBitvector16, nil
+	case Bitvector32:
+		return TypeBitvector32, nil
+	case Bitvector64:
+		return TypeBitvector64, nil
+	case Bitvector128:
+		return TypeBitvector128, nil
+	case Bitvector256:
+		return TypeBitvector256, nil
+	case Bitvector512:
+		return TypeBitvector512, nil
+	default:
+		return 0, ErrUnknownType
+	}
+}
+
+// fromBitlist64 converts a decoded bitlist into a bitfield of a given type. Lengths of bitvector
+// types are validated by DecodeHeader.
+func fromBitlist64(t BitfieldType, b *Bitlist64) Bitfield {
+	switch t {
+	case TypeBitlist:
+		return b.ToBitlist()
+	case TypeBitlist64:
+		return b
+	case TypePersistentBitlist:
+		return NewPersistentBitlistFrom(b)
+	case TypeSparseBitlist:
+		return newSparseFromDense(b)
+	}
+
+	buf := make([]byte, len(b.data)*bytesInWord)
+	for idx, word := range b.data {
+		binary.LittleEndian.PutUint64(buf[idx<<bytesInWordLog2:], word)
+	}
+	buf = buf[:(b.size+7)>>3]
+	switch t {
+	case TypeBitvector4:
+		return Bitvector4(buf)
+	case TypeBitvector8:
+		return Bitvector8(buf)
+	case TypeBitvector16:
+		return Bitvector16(buf)
+	case TypeBitvector32:
+		return Bitvector32(buf)
+	case TypeBitvector64:
+		return Bitvector64(buf)
+	case TypeBitvector128:
+		return Bitvector128(buf)
+	case TypeBitvector256:
+		return Bitvector256(buf)
+	default:
+		return Bitvector512(buf)
+	}
+}
+
+// nextBit returns the index of the first bit at or after `from` having a given value, among the
+// first n bits of a word array. If there is no such bit, n is returned.
+func nextBit(data []uint64, n, from uint64, val bool) uint64 {
+	for from < n {
+		idx := from >> wordSizeLog2
+		word := data[idx]
+		if !val {
+			word = ^word
+		}
+		word &= allBitsSet << (from % wordSize)
+		if word != 0 {
+			if next := idx<<wordSizeLog2 + uint64(bits.TrailingZeros64(word)); next < n {
+				return next
+			}
+			return n
+		}
+		from = (idx + 1) << wordSizeLog2
+	}
+	return n
+}
+
+func appendRaw(buf []byte, data []uint64, n uint64) []byte {
+	numBytes := int((n + 7) >> 3)
+	for i := 0; i<<bytesInWordLog2 < numBytes; i++ {
+		var word [bytesInWord]byte
+		binary.LittleEndian.PutUint64(word[:], data[i])
+		buf = append(buf, word[:min(bytesInWord, numBytes-i<<bytesInWordLog2)]...)
+	}
+	if numBytes > 0 {
+		buf[len(buf)-1] &= lastByteMask(n)
+	}
+	return buf
+}
+
+func appendRLE(buf []byte, data []uint64, n uint64) []byte {
+	var pos uint64
+	val := false
+	for pos < n {
+		next := nextBit(data, n, pos, !val)
+		buf = appendUvarint(buf, next-pos)
+		pos = next
+		val = !val
+	}
+	return buf
+}
+
+func appendSparse(buf []byte, data []uint64, n uint64) []byte {
+	var count uint64
+	for i := nextBit(data, n, 0, true); i < n; i = nextBit(data, n, i+1, true) {
+		count++
+	}
+	buf = appendUvarint(buf, count)
+	var expected uint64
+	for i := nextBit(data, n, 0, true); i < n; i = nextBit(data, n, i+1, true) {
+		buf = appendUvarint(buf, i-expected)
+		expected = i + 1
+	}
+	return buf
+}
+
+func decodeRaw(payload []byte, n uint64) (*Bitlist64, error) {
+	if uint64(len(payload)) != (n+7)>>3 {
+		return nil, ErrInvalidPayload
+	}
+	if len(payload) > 0 && payload[len(payload)-1]&^lastByteMask(n) != 0 {
+		return nil, ErrInvalidPayload
+	}
+	return NewBitlist64FromBytes(n, payload)
+}
+
+func decodeRLE(payload []byte, n uint64) (*Bitlist64, error) {
+	b := NewBitlist64(n)
+	var pos uint64
+	val := false
+	for i := 0; len(payload) > 0; i++ {
+		run, m := binary.Uvarint(payload)
+		// Only the first run may be empty, otherwise runs of the same value must be merged.
+		if m <= 0 || (i > 0 && run == 0) || run > n-pos {
+			return nil, ErrInvalidPayload
+		}
+		payload = payload[m:]
+		if val {
+			flipWordRange(b.data, pos, pos+run)
+		}
+		pos += run
+		val = !val
+	}
+	if pos != n {
+		return nil, ErrInvalidPayload
+	}
+	return b, nil
+}
+
+func decodeSparse(payload []byte, n uint64) (*Bitlist64, error) {
+	count, m := binary.Uvarint(payload)
+	// Every index takes at least a byte, which also protects against huge counts.
+	if m <= 0 || count > n || count > uint64(len(payload)-m) {
+		return nil, ErrInvalidPayload
+	}
+	payload = payload[m:]
+
+	b := NewBitlist64(n)
+	var expected uint64
+	for i := uint64(0); i < count; i++ {
+		gap, m := binary.Uvarint(payload)
+		if m <= 0 || gap >= n-expected {
+			return nil, ErrInvalidPayload
+		}
+		payload = payload[m:]
+		idx := expected + gap
+		b.data[idx>>wordSizeLog2] |= 1 << (idx % wordSize)
+		expected = idx + 1
+	}
+	if len(payload) != 0 {
+		return nil, ErrInvalidPayload
+	}
+	return b, nil
+}
@@ -0,0 +1,262 @@
+package bitfield
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"testing"
+)
+
+// reseal recomputes the checksum of a modified container, so that decoding gets past it.
+func reseal(data []byte) []byte {
+	data = append([]byte(nil), data...)
+	n := len(data) - containerChecksumSize
+	binary.LittleEndian.PutUint32(data[n:], crc32.Checksum(data[:n], castagnoliTable))
+	return data
+}
+
+func TestEncode(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	newBitfields := []func() Bitfield{
+		func() Bitfield { return NewBitlist(0) },
+		func() Bitfield { return NewBitlist(1) },
+		func() Bitfield { return NewBitlist(100) },
+		func() Bitfield { return NewBitlist64(0) },
+		func() Bitfield { return NewBitlist64(64) },
+		func() Bitfield { return NewBitlist64(1000) },
+		func() Bitfield { return NewPersistentBitlist(5000) },
+		func() Bitfield { return NewSparseBitlist(5000) },
+		func() Bitfield { return NewBitvector4() },
+		func() Bitfield { return NewBitvector8() },
+		func() Bitfield { return NewBitvector16() },
+		func() Bitfield { return NewBitvector32() },
+		func() Bitfield { return NewBitvector64() },
+		func() Bitfield { return NewBitvector128() },
+		func() Bitfield { return NewBitvector256() },
+		func() Bitfield { return NewBitvector512() },
+	}
+	for _, newBitfield := range newBitfields {
+		for _, density := range []int{0, 1, 50, 99, 100} {
+			want := newBitfield()
+			randomBits(r, want, density)
+			t.Run(fmt.Sprintf("%T,size:%d,density:%d", want, want.Len(), density), func(t *testing.T) {
+				wantType, err := typeOf(want)
+				if err != nil {
+					t.Fatal(err)
+				}
+				smallest := -1
+				for _, enc := range []PayloadEncoding{EncodingRaw, EncodingRLE, EncodingSparse} {
+					data, err := EncodeWith(want, enc)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if smallest < 0 || len(data) < smallest {
+						smallest = len(data)
+					}
+
+					h, err := DecodeHeader(data)
+					if err != nil {
+						t.Fatal(err)
+					}
+					wantHeader := ContainerHeader{Version: containerVersion, Type: wantType, Encoding: enc, Len: want.Len()}
+					if h != wantHeader {
+						t.Errorf("DecodeHeader() = %+v, wanted %+v", h, wantHeader)
+					}
+
+					got, err := Decode(data)
+					if err != nil {
+						t.Fatalf("Decode() of encoding %d error = %v", enc, err)
+					}
+					if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", want) || !Equal(got, want) {
+						t.Errorf("Decode() of encoding %d = %T %v, wanted %T %v", enc, got, got.BitIndices(), want, want.BitIndices())
+					}
+				}
+
+				data, err := Encode(want)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(data) != smallest {
+					t.Errorf("Encode() size = %d, wanted %d", len(data), smallest)
+				}
+				got, err := Decode(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !Equal(got, want) {
+					t.Errorf("Decode() = %v, wanted %v", got.BitIndices(), want.BitIndices())
+				}
+			})
+		}
+	}
+}
+
+func TestEncode_SmallestEncoding(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	sparse := NewBitlist64(100000)
+	sparse.SetBitAt(5, true)
+	sparse.SetBitAt(70000, true)
+	runs := NewBitlist64(100000)
+	for i := uint64(20000); i < 90000; i++ {
+		runs.SetBitAt(i, true)
+	}
+	dense := NewBitlist64(100000)
+	randomBits(r, dense, 50)
+
+	tests := []struct {
+		name string
+		b    Bitfield
+		want PayloadEncoding
+	}{
+		{name: "sparse", b: sparse, want: EncodingSparse},
+		{name: "runs", b: runs, want: EncodingRLE},
+		{name: "random", b: dense, want: EncodingRaw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h, err := DecodeHeader(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Encoding != tt.want {
+				t.Errorf("Encode() encoding = %d, wanted %d", h.Encoding, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	b := NewBitlist64(100)
+	b.SetBitAt(3, true)
+	b.SetBitAt(50, true)
+	raw, err := EncodeWith(b, EncodingRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rle, err := EncodeWith(b, EncodingRLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sparse, err := EncodeWith(b, EncodingSparse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector, err := EncodeWith(NewBitvector8(), EncodingRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modify := func(data []byte, f func(b []byte) []byte) []byte {
+		return reseal(f(append([]byte(nil), data...)))
+	}
+	withPayload := func(data []byte, payload ...byte) []byte {
+		ret := append([]byte(nil), data[:containerHeaderSize]...)
+		ret = append(ret, payload...)
+		return reseal(append(ret, 0, 0, 0, 0))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "empty", data: []byte{}, want: ErrInvalidMagic},
+		{name: "bad magic", data: modify(raw, func(b []byte) []byte { b[0] = 'X'; return b }), want: ErrInvalidMagic},
+		{name: "truncated", data: raw[:containerHeaderSize], want: ErrTruncated},
+		{name: "flipped payload bit", data: func() []byte {
+			data := append([]byte(nil), raw...)
+			data[containerHeaderSize] ^= 0x01
+			return data
+		}(), want: ErrInvalidChecksum},
+		{name: "flipped checksum bit", data: func() []byte {
+			data := append([]byte(nil), raw...)
+			data[len(data)-1] ^= 0x80
+			return data
+		}(), want: ErrInvalidChecksum},
+		{name: "version", data: modify(raw, func(b []byte) []byte { b[4] = 2; return b }), want: ErrUnsupportedVersion},
+		{name: "reserved", data: modify(raw, func(b []byte) []byte { b[7] = 1; return b }), want: ErrUnsupportedVersion},
+		{name: "unknown type", data: modify(raw, func(b []byte) []byte { b[5] = 0; return b }), want: ErrUnknownType},
+		{name: "unknown type above", data: modify(raw, func(b []byte) []byte { b[5] = byte(TypeSparseBitlist) + 1; return b }), want: ErrUnknownType},
+		{name: "unknown encoding", data: modify(raw, func(b []byte) []byte { b[6] = 3; return b }), want: ErrUnknownEncoding},
+		{name: "bitvector length", data: modify(vector, func(b []byte) []byte { b[8] = 9; return b }), want: ErrInvalidPayload},
+		{name: "raw too short", data: withPayload(raw, raw[containerHeaderSize:len(raw)-5]...), want: ErrInvalidPayload},
+		{name: "raw bits above length", data: modify(raw, func(b []byte) []byte { b[len(b)-5] |= 0x80; return b }), want: ErrInvalidPayload},
+		{name: "rle too short", data: withPayload(rle, 3, 1), want: ErrInvalidPayload},
+		{name: "rle too long", data: withPayload(rle, 3, 1, 200), want: ErrInvalidPayload},
+		{name: "rle empty run", data: withPayload(rle, 3, 0, 97), want: ErrInvalidPayload},
+		{name: "rle malformed", data: withPayload(rle, 3, 0xFF), want: ErrInvalidPayload},
+		{name: "sparse out of range", data: withPayload(sparse, 1, 100), want: ErrInvalidPayload},
+		{name: "sparse missing index", data: withPayload(sparse, 2, 3), want: ErrInvalidPayload},
+		{name: "sparse count above length", data: withPayload(sparse, 101, 0), want: ErrInvalidPayload},
+		{name: "sparse trailing data", data: withPayload(sparse, 1, 3, 0), want: ErrInvalidPayload},
+		{name: "sparse malformed", data: withPayload(sparse), want: ErrInvalidPayload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.data); err != tt.want {
+				t.Errorf("Decode() error = %v, wanted %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("hostile length", func(t *testing.T) {
+		withLen := func(data []byte, n uint64) []byte {
+			return modify(data, func(b []byte) []byte { binary.LittleEndian.PutUint64(b[8:], n); return b })
+		}
+		tests := []struct {
+			name string
+			data []byte
+		}{
+			{name: "raw max uint64", data: withLen(raw, 1<<64-1)},
+			{name: "rle max uint64", data: withLen(rle, 1<<64-1)},
+			{name: "sparse max uint64", data: withLen(sparse, 1<<64-1)},
+			{name: "sparse words overflow int", data: withLen(sparse, 1<<63+1)},
+			{name: "sparse above maximum", data: withLen(sparse, maxContainerLen+1)},
+			{name: "raw longer than payload", data: withLen(raw, 1<<31)},
+			{name: "rle longer than runs", data: withLen(rle, 1<<31)},
+			{name: "rle huge run", data: withLen(withPayload(rle, 0xFF, 0xFF, 0xFF, 0xFF, 0x7F), 1<<35-1)},
+		}
+		for _, tt := range tests {
+			if _, err := DecodeHeader(tt.data); err != ErrInvalidPayload {
+				t.Errorf("%s: DecodeHeader() error = %v, wanted %v", tt.name, err, ErrInvalidPayload)
+			}
+			if _, err := Decode(tt.data); err != ErrInvalidPayload {
+				t.Errorf("%s: Decode() error = %v, wanted %v", tt.name, err, ErrInvalidPayload)
+			}
+		}
+
+		// A long sparse bitfield within the maximum is still decoded.
+		got, err := Decode(withLen(sparse, 1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Len() != 1<<20 || got.Count() != 2 {
+			t.Errorf("Decode() = %d bits with %d set, wanted %d with 2", got.Len(), got.Count(), 1<<20)
+		}
+	})
+
+	t.Run("payload is not modified", func(t *testing.T) {
+		// Decoding a raw payload which is not word aligned must not write past it.
+		data := append([]byte(nil), raw...)
+		if _, err := Decode(data); err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(raw) {
+			t.Error("Decode() modified its input")
+		}
+	})
+
+	t.Run("unknown bitfield type", func(t *testing.T) {
+		if _, err := Encode(&MerkleizedBitlist{}); err != ErrUnknownType {
+			t.Errorf("Encode() error = %v, wanted %v", err, ErrUnknownType)
+		}
+		if _, err := EncodeWith(b, EncodingSparse+1); err != ErrUnknownEncoding {
+			t.Errorf("EncodeWith() error = %v, wanted %v", err, ErrUnknownEncoding)
+		}
+	})
+}
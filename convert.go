@@ -0,0 +1,154 @@
+package bitfield
+
+// Convert copies all bits of src into dst, which may be of any other type e.g. a Bitvector128
+// into a *Bitlist64. Whole bytes or words are copied where layouts of both types allow it.
+// Previous contents of dst are overwritten.
+// This method will return an error if lengths of src and dst differ, or if either of them is a
+// bitvector backed by an array of the wrong length.
+func Convert(src, dst Bitfield) error {
+	n := src.Len()
+	if dst.Len() != n || malformedVector(src) || malformedVector(dst) {
+		return ErrWrongLen
+	}
+
+	if d, ok := dst.(*Bitlist64); ok {
+		words, _ := bitWords(src)
+		copy(d.data, words)
+		d.clearUnusedBits()
+		return nil
+	}
+	if d := rawBytes(dst); d != nil {
+		s, _ := bitBytes(src)
+		copy(d[:n>>3], s)
+		for i := n &^ 7; i < n; i++ {
+			dst.SetBitAt(i, src.BitAt(i))
+		}
+		return nil
+	}
+	return ExtractAt(dst, src, 0)
+}
+
+// BitlistFromVector returns bits of a given bitvector, or any other bitfield, as a bitlist of the
+// same length. This method will return an error if the bitvector is malformed.
+func BitlistFromVector(v Bitfield) (Bitlist, error) {
+	ret := NewBitlist(v.Len())
+	if err := Convert(v, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitlist64FromVector returns bits of a given bitvector, or any other bitfield, as a []uint64
+// backed bitlist of the same length. This method will return an error if the bitvector is
+// malformed.
+func Bitlist64FromVector(v Bitfield) (*Bitlist64, error) {
+	ret := NewBitlist64(v.Len())
+	if err := Convert(v, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector4FromBitlist returns bits of a given bitlist, or any other bitfield of 4 bits, as a
+// bitvector. This method will return an error if the bitlist is not 4 bits long.
+func Bitvector4FromBitlist(b Bitfield) (Bitvector4, error) {
+	ret := NewBitvector4()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector8FromBitlist returns bits of a given bitlist, or any other bitfield of 8 bits, as a
+// bitvector. This method will return an error if the bitlist is not 8 bits long.
+func Bitvector8FromBitlist(b Bitfield) (Bitvector8, error) {
+	ret := NewBitvector8()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector16FromBitlist returns bits of a given bitlist, or any other bitfield of 16 bits, as a
+// bitvector. This method will return an error if the bitlist is not 16 bits long.
+func Bitvector16FromBitlist(b Bitfield) (Bitvector16, error) {
+	ret := NewBitvector16()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector32FromBitlist returns bits of a given bitlist, or any other bitfield of 32 bits, as a
+// bitvector. This method will return an error if the bitlist is not 32 bits long.
+func Bitvector32FromBitlist(b Bitfield) (Bitvector32, error) {
+	ret := NewBitvector32()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector64FromBitlist returns bits of a given bitlist, or any other bitfield of 64 bits, as a
+// bitvector. This method will return an error if the bitlist is not 64 bits long.
+func Bitvector64FromBitlist(b Bitfield) (Bitvector64, error) {
+	ret := NewBitvector64()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector128FromBitlist returns bits of a given bitlist, or any other bitfield of 128 bits, as a
+// bitvector. This method will return an error if the bitlist is not 128 bits long.
+func Bitvector128FromBitlist(b Bitfield) (Bitvector128, error) {
+	ret := NewBitvector128()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector256FromBitlist returns bits of a given bitlist, or any other bitfield of 256 bits, as a
+// bitvector. This method will return an error if the bitlist is not 256 bits long.
+func Bitvector256FromBitlist(b Bitfield) (Bitvector256, error) {
+	ret := NewBitvector256()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bitvector512FromBitlist returns bits of a given bitlist, or any other bitfield of 512 bits, as a
+// bitvector. This method will return an error if the bitlist is not 512 bits long.
+func Bitvector512FromBitlist(b Bitfield) (Bitvector512, error) {
+	ret := NewBitvector512()
+	if err := Convert(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// malformedVector returns true if b is a bitvector backed by an array of the wrong length, which
+// bitvector methods treat as empty.
+func malformedVector(b Bitfield) bool {
+	switch v := b.(type) {
+	case Bitvector4:
+		return len(v) != bitvector4ByteSize
+	case Bitvector8:
+		return len(v) != bitvector8ByteSize
+	case Bitvector16:
+		return len(v) != bitvector16ByteSize
+	case Bitvector32:
+		return len(v) != bitvector32ByteSize
+	case Bitvector64:
+		return len(v) != bitvector64ByteSize
+	case Bitvector128:
+		return len(v) != bitvector128ByteSize
+	case Bitvector256:
+		return len(v) != bitvector256ByteSize
+	case Bitvector512:
+		return len(v) != bitvector512ByteSize
+	}
+	return false
+}
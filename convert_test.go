@@ -0,0 +1,157 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	newBitfields := []func(n uint64) Bitfield{
+		func(n uint64) Bitfield { return NewBitlist(n) },
+		func(n uint64) Bitfield { return NewBitlist64(n) },
+		func(n uint64) Bitfield { return NewSparseBitlist(n) },
+		func(n uint64) Bitfield { return NewPersistentBitlist(n) },
+	}
+	vectors := []func() Bitfield{
+		func() Bitfield { return NewBitvector4() },
+		func() Bitfield { return NewBitvector8() },
+		func() Bitfield { return NewBitvector16() },
+		func() Bitfield { return NewBitvector32() },
+		func() Bitfield { return NewBitvector64() },
+		func() Bitfield { return NewBitvector128() },
+		func() Bitfield { return NewBitvector256() },
+		func() Bitfield { return NewBitvector512() },
+	}
+	for _, newVector := range vectors {
+		newVector := newVector
+		newBitfields = append(newBitfields, func(n uint64) Bitfield {
+			if v := newVector(); v.Len() == n {
+				return v
+			}
+			return nil
+		})
+	}
+
+	for _, n := range []uint64{0, 4, 5, 8, 16, 32, 63, 64, 65, 128, 256, 512, 1000} {
+		for _, newSrc := range newBitfields {
+			for _, newDst := range newBitfields {
+				src, dst := newSrc(n), newDst(n)
+				if src == nil || dst == nil {
+					continue
+				}
+				randomBits(r, src, 50)
+				// Previous contents of dst must be overwritten.
+				randomBits(r, dst, 50)
+				t.Run(fmt.Sprintf("%T,%T,size:%d", src, dst, n), func(t *testing.T) {
+					if err := Convert(src, dst); err != nil {
+						t.Fatal(err)
+					}
+					if !Equal(dst, src) || dst.Count() != src.Count() {
+						t.Errorf("Convert() = %v, wanted %v", dst.BitIndices(), src.BitIndices())
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  Bitfield
+		dst  Bitfield
+	}{
+		{name: "shorter dst", src: NewBitlist(10), dst: NewBitlist64(9)},
+		{name: "longer dst", src: NewBitlist64(10), dst: NewBitlist(11)},
+		{name: "vector", src: NewBitlist(63), dst: NewBitvector64()},
+		{name: "malformed src", src: Bitvector64{0x01}, dst: NewBitlist64(64)},
+		{name: "malformed dst", src: NewBitlist(64), dst: Bitvector64{0x01}},
+		{name: "empty dst", src: NewBitlist(4), dst: Bitvector4{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Convert(tt.src, tt.dst); err != ErrWrongLen {
+				t.Errorf("Convert() error = %v, wanted %v", err, ErrWrongLen)
+			}
+		})
+	}
+}
+
+func TestBitlistFromVector(t *testing.T) {
+	v := NewBitvector128()
+	v.SetBitAt(0, true)
+	v.SetBitAt(127, true)
+
+	b, err := BitlistFromVector(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 128 || !Equal(b, v) {
+		t.Errorf("BitlistFromVector() = %#x, wanted bits of %#x", b, v)
+	}
+	b64, err := Bitlist64FromVector(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&Bitlist64{size: 128, data: []uint64{0x01, 0x8000000000000000}}); !b64.Equal(want) {
+		t.Errorf("Bitlist64FromVector() = %v, wanted %v", b64, want)
+	}
+
+	if _, err := BitlistFromVector(Bitvector128{0x01}); err != ErrWrongLen {
+		t.Errorf("BitlistFromVector() error = %v, wanted %v", err, ErrWrongLen)
+	}
+	if _, err := Bitlist64FromVector(Bitvector128{0x01}); err != ErrWrongLen {
+		t.Errorf("Bitlist64FromVector() error = %v, wanted %v", err, ErrWrongLen)
+	}
+}
+
+func TestBitvectorFromBitlist(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(b Bitfield) (Bitfield, error)
+		size uint64
+	}{
+		{name: "Bitvector4", f: func(b Bitfield) (Bitfield, error) { return Bitvector4FromBitlist(b) }, size: 4},
+		{name: "Bitvector8", f: func(b Bitfield) (Bitfield, error) { return Bitvector8FromBitlist(b) }, size: 8},
+		{name: "Bitvector16", f: func(b Bitfield) (Bitfield, error) { return Bitvector16FromBitlist(b) }, size: 16},
+		{name: "Bitvector32", f: func(b Bitfield) (Bitfield, error) { return Bitvector32FromBitlist(b) }, size: 32},
+		{name: "Bitvector64", f: func(b Bitfield) (Bitfield, error) { return Bitvector64FromBitlist(b) }, size: 64},
+		{name: "Bitvector128", f: func(b Bitfield) (Bitfield, error) { return Bitvector128FromBitlist(b) }, size: 128},
+		{name: "Bitvector256", f: func(b Bitfield) (Bitfield, error) { return Bitvector256FromBitlist(b) }, size: 256},
+		{name: "Bitvector512", f: func(b Bitfield) (Bitfield, error) { return Bitvector512FromBitlist(b) }, size: 512},
+	}
+	r := rand.New(rand.NewSource(42))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBitlist(tt.size)
+			randomBits(r, b, 50)
+			got, err := tt.f(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprintf("%T", got) != "bitfield."+tt.name || !Equal(got, b) {
+				t.Errorf("FromBitlist() = %T %v, wanted %v", got, got.BitIndices(), b.BitIndices())
+			}
+
+			b64, err := b.ToBitlist64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err = tt.f(b64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(got, b) {
+				t.Errorf("FromBitlist() of a Bitlist64 = %v, wanted %v", got.BitIndices(), b.BitIndices())
+			}
+
+			for _, n := range []uint64{tt.size - 1, tt.size + 1} {
+				if _, err := tt.f(NewBitlist(n)); err != ErrWrongLen {
+					t.Errorf("FromBitlist() of %d bits error = %v, wanted %v", n, err, ErrWrongLen)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package bitfield
+
+import (
+	"math/bits"
+)
+
+const (
+	// minCounterWidth and maxCounterWidth define the allowed number of bits per counter.
+	minCounterWidth = 2
+	maxCounterWidth = 8
+)
+
+// CountingBitlist is a list of small saturating counters, one per index, packed into an array of
+// uint64. It extends a Bitlist64 from membership to multiplicity e.g. how many aggregates included
+// a given validator. Counters never straddle a word boundary, so every word holds 64/width counters.
+type CountingBitlist struct {
+	size    uint64
+	width   uint64
+	perWord uint64
+	max     uint64
+	data    []uint64
+}
+
+// NewCountingBitlist creates a new list of `n` counters, each `width` bits wide.
+// This method will return an error if width is not between 2 and 8.
+func NewCountingBitlist(n uint64, width uint8) (*CountingBitlist, error) {
+	if width < minCounterWidth || width > maxCounterWidth {
+		return nil, ErrInvalidCounterWidth
+	}
+	w := uint64(width)
+	perWord := wordSize / w
+	return &CountingBitlist{
+		size:    n,
+		width:   w,
+		perWord: perWord,
+		max:     1<<w - 1,
+		data:    make([]uint64, (n+perWord-1)/perWord),
+	}, nil
+}
+
+// Len returns the number of counters.
+func (b *CountingBitlist) Len() uint64 {
+	return b.size
+}
+
+// Width returns the number of bits per counter.
+func (b *CountingBitlist) Width() uint8 {
+	return uint8(b.width)
+}
+
+// Max returns the value at which counters saturate.
+func (b *CountingBitlist) Max() uint8 {
+	return uint8(b.max)
+}
+
+// Get returns the counter at the given index. If the index requested exceeds the number of
+// counters, then this method returns 0.
+func (b *CountingBitlist) Get(idx uint64) uint8 {
+	if idx >= b.size {
+		return 0
+	}
+	word, shift := b.position(idx)
+	return uint8((b.data[word] >> shift) & b.max)
+}
+
+// Inc increments the counter at the given index, unless it is already saturated. If the index
+// requested exceeds the number of counters, then this method does nothing.
+func (b *CountingBitlist) Inc(idx uint64) {
+	if idx >= b.size {
+		return
+	}
+	word, shift := b.position(idx)
+	if (b.data[word]>>shift)&b.max != b.max {
+		b.data[word] += 1 << shift
+	}
+}
+
+// Dec decrements the counter at the given index, unless it is already zero. If the index
+// requested exceeds the number of counters, then this method does nothing.
+func (b *CountingBitlist) Dec(idx uint64) {
+	if idx >= b.size {
+		return
+	}
+	word, shift := b.position(idx)
+	if (b.data[word]>>shift)&b.max != 0 {
+		b.data[word] -= 1 << shift
+	}
+}
+
+// AddFrom increments the counters at every index set in a given bitlist, in a single pass over
+// its words. This method will return an error if the lengths are not the same.
+func (b *CountingBitlist) AddFrom(c *Bitlist64) error {
+	if b.size != c.Len() {
+		return ErrBitlistDifferentLength
+	}
+	numWords := numWordsRequired(c.size)
+	for idx := 0; idx < numWords; idx++ {
+		word := c.data[idx]
+		if idx == numWords-1 {
+			word &= lastWordMask(c.size)
+		}
+		for word != 0 {
+			b.Inc(uint64(idx)<<wordSizeLog2 + uint64(bits.TrailingZeros64(word)))
+			word &= word - 1
+		}
+	}
+	return nil
+}
+
+// Threshold returns a bitlist with bits set at every index whose counter is at least k.
+func (b *CountingBitlist) Threshold(k uint8) *Bitlist64 {
+	ret := NewBitlist64(b.size)
+	for idx := uint64(0); idx < b.size; idx++ {
+		// Skip all-zero words at once, as counting lists are usually sparse.
+		if idx%b.perWord == 0 && k > 0 && b.data[idx/b.perWord] == 0 {
+			idx += b.perWord - 1
+			continue
+		}
+		if b.Get(idx) >= k {
+			ret.data[idx>>wordSizeLog2] |= 1 << (idx % wordSize)
+		}
+	}
+	return ret
+}
+
+// position returns the word index and the bit offset of a counter at the given index.
+func (b *CountingBitlist) position(idx uint64) (int, uint64) {
+	return int(idx / b.perWord), (idx % b.perWord) * b.width
+}
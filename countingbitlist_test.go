@@ -0,0 +1,164 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestCountingBitlist_NewCountingBitlist(t *testing.T) {
+	for _, width := range []uint8{0, 1, 9, 64} {
+		if _, err := NewCountingBitlist(10, width); err != ErrInvalidCounterWidth {
+			t.Errorf("NewCountingBitlist(10, %d) error = %v, wanted %v", width, err, ErrInvalidCounterWidth)
+		}
+	}
+
+	tests := []struct {
+		n        uint64
+		width    uint8
+		numWords int
+		max      uint8
+	}{
+		{n: 0, width: 2, numWords: 0, max: 3},
+		{n: 32, width: 2, numWords: 1, max: 3},
+		{n: 33, width: 2, numWords: 2, max: 3},
+		{n: 21, width: 3, numWords: 1, max: 7},
+		{n: 22, width: 3, numWords: 2, max: 7},
+		{n: 100, width: 5, numWords: 9, max: 31},
+		{n: 16, width: 8, numWords: 2, max: 255},
+	}
+	for _, tt := range tests {
+		b, err := NewCountingBitlist(tt.n, tt.width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b.data) != tt.numWords || b.Max() != tt.max || b.Len() != tt.n || b.Width() != tt.width {
+			t.Errorf("NewCountingBitlist(%d, %d) = %+v, wanted %d words, max %d", tt.n, tt.width, b, tt.numWords, tt.max)
+		}
+	}
+}
+
+func TestCountingBitlist_IncDec(t *testing.T) {
+	for width := uint8(2); width <= 8; width++ {
+		t.Run(fmt.Sprintf("width:%d", width), func(t *testing.T) {
+			b, err := NewCountingBitlist(130, width)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := rand.New(rand.NewSource(int64(width)))
+			want := make([]int, b.Len())
+			for i := 0; i < 20000; i++ {
+				idx := uint64(r.Intn(int(b.Len())))
+				if r.Intn(3) == 0 {
+					b.Dec(idx)
+					if want[idx] > 0 {
+						want[idx]--
+					}
+				} else {
+					b.Inc(idx)
+					if want[idx] < int(b.Max()) {
+						want[idx]++
+					}
+				}
+			}
+			for idx := range want {
+				if got := b.Get(uint64(idx)); int(got) != want[idx] {
+					t.Errorf("Get(%d) = %d, wanted %d", idx, got, want[idx])
+				}
+			}
+		})
+	}
+
+	t.Run("saturation", func(t *testing.T) {
+		b, _ := NewCountingBitlist(3, 2)
+		for i := 0; i < 10; i++ {
+			b.Inc(1)
+		}
+		if b.Get(0) != 0 || b.Get(1) != 3 || b.Get(2) != 0 {
+			t.Errorf("Counters = %d, %d, %d, wanted 0, 3, 0", b.Get(0), b.Get(1), b.Get(2))
+		}
+		for i := 0; i < 10; i++ {
+			b.Dec(1)
+		}
+		if b.Get(0) != 0 || b.Get(1) != 0 || b.Get(2) != 0 {
+			t.Errorf("Counters = %d, %d, %d, wanted 0, 0, 0", b.Get(0), b.Get(1), b.Get(2))
+		}
+	})
+
+	t.Run("out of bounds", func(t *testing.T) {
+		b, _ := NewCountingBitlist(3, 4)
+		b.Inc(3)
+		b.Dec(3)
+		if b.Get(3) != 0 || b.data[0] != 0 {
+			t.Errorf("Out of bounds access modified counters: %x", b.data)
+		}
+	})
+}
+
+func TestCountingBitlist_AddFrom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 63, 64, 65, 1000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			b, _ := NewCountingBitlist(n, 3)
+			want := make([]uint8, n)
+			for i := 0; i < 10; i++ {
+				bl := NewBitlist64(n)
+				randomBits(r, bl, 30)
+				if err := b.AddFrom(bl); err != nil {
+					t.Fatal(err)
+				}
+				for _, idx := range bl.BitIndices() {
+					if want[idx] < b.Max() {
+						want[idx]++
+					}
+				}
+			}
+			for idx := range want {
+				if got := b.Get(uint64(idx)); got != want[idx] {
+					t.Errorf("Get(%d) = %d, wanted %d", idx, got, want[idx])
+				}
+			}
+		})
+	}
+
+	t.Run("bits above size are ignored", func(t *testing.T) {
+		b, _ := NewCountingBitlist(4, 2)
+		if err := b.AddFrom(&Bitlist64{size: 4, data: []uint64{0xF1}}); err != nil {
+			t.Fatal(err)
+		}
+		if b.data[0] != 0x01 {
+			t.Errorf("data = %x, wanted 01", b.data)
+		}
+	})
+
+	t.Run("check errors", func(t *testing.T) {
+		b, _ := NewCountingBitlist(64, 2)
+		if err := b.AddFrom(NewBitlist64(65)); err != ErrBitlistDifferentLength {
+			t.Errorf("Wrong error returned. Wanted %v, got %v", ErrBitlistDifferentLength, err)
+		}
+	})
+}
+
+func TestCountingBitlist_Threshold(t *testing.T) {
+	b, _ := NewCountingBitlist(100, 4)
+	for i := uint64(0); i < 100; i++ {
+		for j := uint64(0); j < i%7; j++ {
+			b.Inc(i)
+		}
+	}
+	for k := uint8(0); k <= 8; k++ {
+		got := b.Threshold(k)
+		if got.Len() != 100 {
+			t.Fatalf("Threshold(%d).Len() = %d, wanted 100", k, got.Len())
+		}
+		for i := uint64(0); i < 100; i++ {
+			if want := uint8(i%7) >= k; got.BitAt(i) != want {
+				t.Errorf("Threshold(%d).BitAt(%d) = %t, wanted %t", k, i, got.BitAt(i), want)
+			}
+		}
+		// Bits above size must stay clear.
+		if got.Count() != uint64(len(got.BitIndices())) || got.data[1]>>36 != 0 {
+			t.Errorf("Threshold(%d) has bits set above size: %x", k, got.data)
+		}
+	}
+}
@@ -6,4 +6,28 @@ var (
 	ErrBitlistDifferentLength   = errors.New("bitlists are different lengths")
 	ErrBitvectorDifferentLength = errors.New("bitvectors are different lengths")
 	ErrWrongLen                 = errors.New("bitvector is wrong length")
+	ErrWeightsTooShort          = errors.New("weights are shorter than bitlist")
+	ErrWeightedSumOverflow      = errors.New("weighted sum overflows uint64")
+	ErrInvalidCounterWidth      = errors.New("counter width must be between 2 and 8 bits")
+	ErrInvalidFlag              = errors.New("flag index exceeds the number of flags")
+	ErrInvalidCommitteeIndex    = errors.New("committee index exceeds the number of committees")
+	ErrDuplicateCommittee       = errors.New("committee is included more than once")
+	ErrExceedsLimit             = errors.New("bitlist length exceeds limit")
+	ErrInvalidPatch             = errors.New("invalid patch encoding")
+	ErrMissingLengthBit         = errors.New("bitlist is missing the length bit")
+	ErrInvalidMappedFile        = errors.New("file does not hold a mapped bitlist")
+	ErrChecksumMismatch         = errors.New("mapped bitlist checksum mismatch")
+	ErrReadOnly                 = errors.New("bitlist is read-only")
+	ErrInvalidMagic             = errors.New("data is not a bitfield container")
+	ErrUnsupportedVersion       = errors.New("unsupported bitfield container version")
+	ErrUnknownType              = errors.New("unknown bitfield type")
+	ErrUnknownEncoding          = errors.New("unknown bitfield payload encoding")
+	ErrTruncated                = errors.New("bitfield container is truncated")
+	ErrInvalidPayload           = errors.New("invalid bitfield container payload")
+	ErrInvalidChecksum          = errors.New("bitfield container checksum mismatch")
+	ErrInvalidIndices           = errors.New("indices are unsorted, duplicated or out of range")
+	ErrInvalidIndexEncoding     = errors.New("invalid index list encoding")
+	ErrBigIntOutOfRange         = errors.New("integer is negative or exceeds the bitfield length")
+	ErrInvalidExpr              = errors.New("invalid bitfield expression")
+	ErrUnknownOperand           = errors.New("unknown expression operand")
 )
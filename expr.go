@@ -0,0 +1,293 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// exprBlockWords is the number of words each instruction of an expression processes at once,
+// amortizing the cost of interpreting instructions.
+const exprBlockWords = 32
+
+type exprOp uint8
+
+const (
+	exprLoad exprOp = iota
+	exprNot
+	exprAnd
+	exprOr
+	exprXor
+)
+
+// exprInstr is an instruction of a compiled expression, operating on a stack of blocks of words.
+// Binary operations combine the top two blocks, unless arg refers to an operand, in which case
+// the top block is combined with the operand directly, saving a copy.
+type exprInstr struct {
+	op  exprOp
+	arg int // Index of the operand, or -1.
+}
+
+// Expr is a compiled expression over named bitlists, such as `(source & target & ~slashed) | head`.
+// It is evaluated in a single pass over words of all operands, without allocating intermediate
+// bitlists. Operands are bound when the expression is compiled, so changes to their bits are
+// reflected by every evaluation.
+//
+// Expressions support `~` (not), `&` (and), `^` (xor) and `|` (or), in order of decreasing
+// precedence as in Go and C, and parentheses. Operand names consist of letters, digits, `_` and
+// `.`, and do not start with a digit.
+type Expr struct {
+	size     uint64
+	operands []*Bitlist64
+	code     []exprInstr
+	depth    int
+}
+
+// CompileExpr parses an expression, binding names it refers to to given operands.
+// This method will return an error if the expression is malformed, refers to an operand which is
+// not given, or if the operands it refers to are not the same length.
+func CompileExpr(expr string, operands map[string]*Bitlist64) (*Expr, error) {
+	p := &exprParser{expr: expr, operands: operands, indices: make(map[string]int)}
+	e := &Expr{}
+	p.e = e
+	if err := p.parseOr(); err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(expr) {
+		return nil, p.unexpected()
+	}
+	return e, nil
+}
+
+// Len returns the number of bits of the operands, and of the result.
+func (e *Expr) Len() uint64 {
+	return e.size
+}
+
+// Eval evaluates the expression, storing the result in dst. Dst may be one of the operands.
+// This method will return an error if dst is not the same length as the operands.
+func (e *Expr) Eval(dst *Bitlist64) error {
+	if dst.size != e.size {
+		return ErrBitlistDifferentLength
+	}
+	stack := e.newStack()
+	for base := 0; base < len(dst.data); base += exprBlockWords {
+		copy(dst.data[base:], e.evalBlock(stack, base))
+	}
+	return nil
+}
+
+// EvalCount evaluates the expression, returning the number of 1s in the result.
+func (e *Expr) EvalCount() uint64 {
+	var c int
+	stack := e.newStack()
+	for base := 0; base < numWordsRequired(e.size); base += exprBlockWords {
+		for _, word := range e.evalBlock(stack, base) {
+			c += bits.OnesCount64(word)
+		}
+	}
+	return uint64(c)
+}
+
+// EvalIndices evaluates the expression, returning the list of indices of bits set in the result.
+func (e *Expr) EvalIndices() []int {
+	indices := make([]int, 0)
+	stack := e.newStack()
+	for base := 0; base < numWordsRequired(e.size); base += exprBlockWords {
+		for idx, word := range e.evalBlock(stack, base) {
+			for word != 0 {
+				indices = append(indices, (base+idx)<<wordSizeLog2+bits.TrailingZeros64(word))
+				word &= word - 1
+			}
+		}
+	}
+	return indices
+}
+
+// newStack allocates the stack of blocks needed to evaluate the expression.
+func (e *Expr) newStack() []uint64 {
+	return make([]uint64, e.depth*exprBlockWords)
+}
+
+// evalBlock evaluates the expression for the block of words starting at word base, returning the
+// result at the bottom of the stack. Bits above the length are cleared.
+func (e *Expr) evalBlock(stack []uint64, base int) []uint64 {
+	numWords := numWordsRequired(e.size)
+	n := min(exprBlockWords, numWords-base)
+	sp := 0
+	for _, in := range e.code {
+		if in.op == exprLoad {
+			copy(stack[sp*exprBlockWords:sp*exprBlockWords+n], e.operands[in.arg].data[base:base+n])
+			sp++
+			continue
+		}
+		top := stack[(sp-1)*exprBlockWords : (sp-1)*exprBlockWords+n]
+		if in.op == exprNot {
+			for i := range top {
+				top[i] = ^top[i]
+			}
+			continue
+		}
+
+		var other []uint64
+		if in.arg >= 0 {
+			other = e.operands[in.arg].data[base : base+n]
+		} else {
+			other = top
+			sp--
+			top = stack[(sp-1)*exprBlockWords : (sp-1)*exprBlockWords+n]
+		}
+		switch in.op {
+		case exprAnd:
+			for i := range top {
+				top[i] &= other[i]
+			}
+		case exprOr:
+			for i := range top {
+				top[i] |= other[i]
+			}
+		case exprXor:
+			for i := range top {
+				top[i] ^= other[i]
+			}
+		}
+	}
+
+	ret := stack[:n]
+	if base+n == numWords {
+		ret[n-1] &= lastWordMask(e.size)
+	}
+	return ret
+}
+
+// exprParser is a recursive descent parser, emitting instructions of the expression as it goes.
+type exprParser struct {
+	expr     string
+	pos      int
+	operands map[string]*Bitlist64
+	indices  map[string]int // Indices of operands already bound.
+	e        *Expr
+	sp       int // Depth of the stack after executing instructions emitted so far.
+}
+
+// parseOr parses `xor ('|' xor)*`.
+func (p *exprParser) parseOr() error {
+	return p.parseBinary('|', exprOr, p.parseXor)
+}
+
+// parseXor parses `and ('^' and)*`.
+func (p *exprParser) parseXor() error {
+	return p.parseBinary('^', exprXor, p.parseAnd)
+}
+
+// parseAnd parses `unary ('&' unary)*`.
+func (p *exprParser) parseAnd() error {
+	return p.parseBinary('&', exprAnd, p.parseUnary)
+}
+
+// parseBinary parses a left-associative chain of operands separated by a given operator.
+func (p *exprParser) parseBinary(sep byte, op exprOp, parseOperand func() error) error {
+	if err := parseOperand(); err != nil {
+		return err
+	}
+	for p.skipSpace(); p.pos < len(p.expr) && p.expr[p.pos] == sep; p.skipSpace() {
+		p.pos++
+		if err := parseOperand(); err != nil {
+			return err
+		}
+		// Combine with an operand directly, rather than loading it onto the stack first.
+		// Instructions of a compound right operand never end with a load.
+		if last := &p.e.code[len(p.e.code)-1]; last.op == exprLoad {
+			last.op = op
+			p.sp--
+			continue
+		}
+		p.emit(exprInstr{op: op, arg: -1})
+		p.sp--
+	}
+	return nil
+}
+
+// parseUnary parses `'~' unary | '(' or ')' | name`.
+func (p *exprParser) parseUnary() error {
+	p.skipSpace()
+	if p.pos == len(p.expr) {
+		return p.unexpected()
+	}
+	switch c := p.expr[p.pos]; {
+	case c == '~':
+		p.pos++
+		if err := p.parseUnary(); err != nil {
+			return err
+		}
+		p.emit(exprInstr{op: exprNot, arg: -1})
+		return nil
+	case c == '(':
+		p.pos++
+		if err := p.parseOr(); err != nil {
+			return err
+		}
+		if p.skipSpace(); p.pos == len(p.expr) || p.expr[p.pos] != ')' {
+			return p.unexpected()
+		}
+		p.pos++
+		return nil
+	case isNameStart(c):
+		start := p.pos
+		for p.pos < len(p.expr) && (isNameStart(p.expr[p.pos]) || isDigit(p.expr[p.pos])) {
+			p.pos++
+		}
+		return p.load(p.expr[start:p.pos])
+	default:
+		return p.unexpected()
+	}
+}
+
+// load emits an instruction loading a named operand, binding it on first use.
+func (p *exprParser) load(name string) error {
+	idx, ok := p.indices[name]
+	if !ok {
+		b := p.operands[name]
+		if b == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownOperand, name)
+		}
+		if len(p.e.operands) > 0 && b.size != p.e.size {
+			return ErrBitlistDifferentLength
+		}
+		idx = len(p.e.operands)
+		p.indices[name] = idx
+		p.e.operands = append(p.e.operands, b)
+		p.e.size = b.size
+	}
+	p.emit(exprInstr{op: exprLoad, arg: idx})
+	p.sp++
+	if p.sp > p.e.depth {
+		p.e.depth = p.sp
+	}
+	return nil
+}
+
+func (p *exprParser) emit(in exprInstr) {
+	p.e.code = append(p.e.code, in)
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.expr) && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t' || p.expr[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// unexpected returns an error pointing at the current position of the parser.
+func (p *exprParser) unexpected() error {
+	if p.pos == len(p.expr) {
+		return fmt.Errorf("%w: unexpected end of expression", ErrInvalidExpr)
+	}
+	return fmt.Errorf("%w: unexpected %q at offset %d", ErrInvalidExpr, p.expr[p.pos], p.pos)
+}
+
+func isNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '.'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,156 @@
+package bitfield
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestExpr(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	tests := []struct {
+		expr  string
+		naive func(a, b, c, d bool) bool
+	}{
+		{expr: "a", naive: func(a, b, c, d bool) bool { return a }},
+		{expr: "~a", naive: func(a, b, c, d bool) bool { return !a }},
+		{expr: "~~a", naive: func(a, b, c, d bool) bool { return a }},
+		{expr: "a & b", naive: func(a, b, c, d bool) bool { return a && b }},
+		{expr: "a | b", naive: func(a, b, c, d bool) bool { return a || b }},
+		{expr: "a ^ b", naive: func(a, b, c, d bool) bool { return a != b }},
+		{expr: "a & a", naive: func(a, b, c, d bool) bool { return a }},
+		{expr: "a & ~b", naive: func(a, b, c, d bool) bool { return a && !b }},
+		{expr: "(a & b & ~c) | d", naive: func(a, b, c, d bool) bool { return (a && b && !c) || d }},
+		{expr: "a | b & c", naive: func(a, b, c, d bool) bool { return a || (b && c) }},
+		{expr: "a ^ b & c | d", naive: func(a, b, c, d bool) bool { return (a != (b && c)) || d }},
+		{expr: "a & (b | (c ^ (d)))", naive: func(a, b, c, d bool) bool { return a && (b || (c != d)) }},
+		{expr: "~(a | b) ^ ~(c & d)", naive: func(a, b, c, d bool) bool { return !(a || b) != !(c && d) }},
+		{expr: "\ta&b|c&d\n", naive: func(a, b, c, d bool) bool { return (a && b) || (c && d) }},
+	}
+	for _, n := range []uint64{0, 1, 63, 64, 65, exprBlockWords * 64, exprBlockWords*64 + 1, 5000} {
+		operands := map[string]*Bitlist64{}
+		for _, name := range []string{"a", "b", "c", "d"} {
+			operands[name] = NewBitlist64(n)
+			randomBits(r, operands[name], 50)
+		}
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%q,size:%d", tt.expr, n), func(t *testing.T) {
+				e, err := CompileExpr(tt.expr, operands)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := NewBitlist64(n)
+				for i := uint64(0); i < n; i++ {
+					want.SetBitAt(i, tt.naive(operands["a"].BitAt(i), operands["b"].BitAt(i), operands["c"].BitAt(i), operands["d"].BitAt(i)))
+				}
+
+				if e.Len() != n {
+					t.Errorf("Len() = %d, wanted %d", e.Len(), n)
+				}
+				got := NewBitlist64(n)
+				if err := e.Eval(got); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Eval() = %v, wanted %v", got.BitIndices(), want.BitIndices())
+				}
+				if e.EvalCount() != want.Count() {
+					t.Errorf("EvalCount() = %d, wanted %d", e.EvalCount(), want.Count())
+				}
+				if !reflect.DeepEqual(e.EvalIndices(), want.BitIndices()) {
+					t.Errorf("EvalIndices() = %v, wanted %v", e.EvalIndices(), want.BitIndices())
+				}
+			})
+		}
+	}
+}
+
+func TestExpr_Operands(t *testing.T) {
+	a, b := NewBitlist64(100), NewBitlist64(100)
+	a.SetBitAt(1, true)
+	a.SetBitAt(2, true)
+	b.SetBitAt(2, true)
+	e, err := CompileExpr("a & ~b", map[string]*Bitlist64{"a": a, "b": b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes to operands are reflected by later evaluations.
+	b.SetBitAt(1, true)
+	b.SetBitAt(2, false)
+	if got, want := e.EvalIndices(), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EvalIndices() = %v, wanted %v", got, want)
+	}
+
+	// The result may be stored in one of the operands.
+	if err := e.Eval(a); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.BitIndices(), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Eval() = %v, wanted %v", got, want)
+	}
+
+	if err := e.Eval(NewBitlist64(101)); err != ErrBitlistDifferentLength {
+		t.Errorf("Eval() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+	}
+}
+
+func TestExpr_Allocations(t *testing.T) {
+	operands := map[string]*Bitlist64{
+		"source":  NewBitlist64(100000),
+		"target":  NewBitlist64(100000),
+		"slashed": NewBitlist64(100000),
+		"head":    NewBitlist64(100000),
+	}
+	e, err := CompileExpr("(source & target & ~slashed) | head", operands)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := NewBitlist64(100000)
+	// Only the stack of blocks is allocated, regardless of the length and the expression.
+	if allocs := testing.AllocsPerRun(10, func() { _ = e.Eval(dst) }); allocs > 1 {
+		t.Errorf("Eval() allocations = %v, wanted at most 1", allocs)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { _ = e.EvalCount() }); allocs > 1 {
+		t.Errorf("EvalCount() allocations = %v, wanted at most 1", allocs)
+	}
+}
+
+func TestCompileExpr_Errors(t *testing.T) {
+	operands := map[string]*Bitlist64{
+		"a":     NewBitlist64(10),
+		"b_2.x": NewBitlist64(10),
+		"long":  NewBitlist64(11),
+	}
+	tests := []struct {
+		expr string
+		want error
+	}{
+		{expr: "", want: ErrInvalidExpr},
+		{expr: "  ", want: ErrInvalidExpr},
+		{expr: "a &", want: ErrInvalidExpr},
+		{expr: "a b_2.x", want: ErrInvalidExpr},
+		{expr: "(a", want: ErrInvalidExpr},
+		{expr: "a)", want: ErrInvalidExpr},
+		{expr: "()", want: ErrInvalidExpr},
+		{expr: "a && b_2.x", want: ErrInvalidExpr},
+		{expr: "a + b_2.x", want: ErrInvalidExpr},
+		{expr: "~", want: ErrInvalidExpr},
+		{expr: "2a", want: ErrInvalidExpr},
+		{expr: "a & c", want: ErrUnknownOperand},
+		{expr: "a | long", want: ErrBitlistDifferentLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, err := CompileExpr(tt.expr, operands); !errors.Is(err, tt.want) {
+				t.Errorf("CompileExpr() error = %v, wanted %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := CompileExpr("a & ~b_2.x", operands); err != nil {
+		t.Errorf("CompileExpr() error = %v", err)
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/theQRL/go-bitfield
 
 go 1.16
+
+require golang.org/x/sys v0.0.0-20210510120138-977fb7262007
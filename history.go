@@ -0,0 +1,77 @@
+package bitfield
+
+// BitHistory records a single bit per epoch into a fixed width bitfield (typically a BitvectorN),
+// generalizing justification bits to any width. Bit 0 always holds the most recent record, bit 1
+// the one before it, and so on. Records older than the width of the bitfield are dropped.
+type BitHistory struct {
+	bits Bitfield
+}
+
+// NewBitHistory creates a history backed by a given bitfield e.g. NewBitHistory(NewBitvector64())
+// keeps the last 64 epochs. The bitfield is used in place, its current bits become the history.
+func NewBitHistory(b Bitfield) *BitHistory {
+	return &BitHistory{
+		bits: b,
+	}
+}
+
+// Record shifts all bits by one towards higher indices, dropping the oldest bit, and sets bit 0 to
+// the given value.
+func (h *BitHistory) Record(bit bool) {
+	n := h.bits.Len()
+	if n == 0 {
+		return
+	}
+
+	if raw := rawBytes(h.bits); raw != nil && n%8 == 0 {
+		// Shift whole bytes, carrying the most significant bit into the next byte. The byte holding
+		// the length bit of a bitlist is left alone.
+		var carry byte
+		if bit {
+			carry = 1
+		}
+		for i := range raw[:n>>3] {
+			raw[i], carry = raw[i]<<1|carry, raw[i]>>7
+		}
+		return
+	}
+
+	for i := n - 1; i > 0; i-- {
+		h.bits.SetBitAt(i, h.bits.BitAt(i-1))
+	}
+	h.bits.SetBitAt(0, bit)
+}
+
+// Len returns the number of epochs kept in the history.
+func (h *BitHistory) Len() uint64 {
+	return h.bits.Len()
+}
+
+// At returns the bit recorded `age` epochs ago, where age 0 is the most recent record.
+func (h *BitHistory) At(age uint64) bool {
+	return h.bits.BitAt(age)
+}
+
+// AllSetInRange returns true if all bits in the half-open range of ages [lo, hi) are set. An empty
+// range is never considered set.
+func (h *BitHistory) AllSetInRange(lo, hi uint64) bool {
+	if lo >= hi || hi > h.bits.Len() {
+		return false
+	}
+	for i := lo; i < hi; i++ {
+		if !h.bits.BitAt(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of bits set in the history.
+func (h *BitHistory) Count() uint64 {
+	return h.bits.Count()
+}
+
+// Bits returns the underlying bitfield. The bitfield is not copied.
+func (h *BitHistory) Bits() Bitfield {
+	return h.bits
+}
@@ -0,0 +1,74 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestBitHistory_Record(t *testing.T) {
+	tests := []func() Bitfield{
+		func() Bitfield { return NewBitvector4() },
+		func() Bitfield { return NewBitvector8() },
+		func() Bitfield { return NewBitvector16() },
+		func() Bitfield { return NewBitvector32() },
+		func() Bitfield { return NewBitvector64() },
+		func() Bitfield { return NewBitvector128() },
+		func() Bitfield { return NewBitvector256() },
+		func() Bitfield { return NewBitvector512() },
+		func() Bitfield { return NewBitlist(13) },
+		func() Bitfield { return NewBitlist(8) },
+		func() Bitfield { return NewBitlist(64) },
+		func() Bitfield { return NewBitlist64(100) },
+	}
+
+	r := rand.New(rand.NewSource(42))
+	for _, newBits := range tests {
+		h := NewBitHistory(newBits())
+		t.Run(fmt.Sprintf("%T", h.Bits()), func(t *testing.T) {
+			var records []bool
+			for i := 0; i < 600; i++ {
+				bit := r.Intn(2) == 1
+				records = append(records, bit)
+				h.Record(bit)
+			}
+			for age := uint64(0); age < h.Len(); age++ {
+				if want := records[len(records)-1-int(age)]; h.At(age) != want {
+					t.Fatalf("At(%d) = %t, wanted %t", age, h.At(age), want)
+				}
+			}
+			if h.At(h.Len()) {
+				t.Error("At(Len()) = true, wanted false")
+			}
+			if h.Len() != newBits().Len() {
+				t.Errorf("Len() = %d, wanted %d", h.Len(), newBits().Len())
+			}
+		})
+	}
+}
+
+func TestBitHistory_AllSetInRange(t *testing.T) {
+	h := NewBitHistory(NewBitvector16())
+	for _, bit := range []bool{true, true, true, false, true, true} {
+		h.Record(bit)
+	}
+	tests := []struct {
+		lo, hi uint64
+		want   bool
+	}{
+		{lo: 0, hi: 2, want: true},
+		{lo: 0, hi: 3, want: false},
+		{lo: 3, hi: 6, want: true},
+		{lo: 3, hi: 7, want: false},
+		{lo: 1, hi: 1, want: false},
+		{lo: 15, hi: 17, want: false},
+	}
+	for _, tt := range tests {
+		if got := h.AllSetInRange(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("AllSetInRange(%d, %d) = %t, wanted %t", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+	if h.Count() != 5 {
+		t.Errorf("Count() = %d, wanted 5", h.Count())
+	}
+}
@@ -0,0 +1,110 @@
+package bitfield
+
+import "encoding/binary"
+
+const (
+	// indicesPlain marks an index list encoded as one gap per index.
+	indicesPlain = byte(0)
+	// indicesZeroRuns marks an index list in which runs of zero gaps are packed.
+	indicesZeroRuns = byte(1)
+)
+
+// EncodeIndices encodes a sorted list of indices of set bits, e.g. as returned by BitIndices, as a
+// flag byte and uvarints: the number of indices, followed by gaps between consecutive indices
+// (an index right after the previous one has a gap of zero).
+//
+// If packZeroRuns is true, every run of zero gaps is packed as a zero byte followed by the length
+// of the run, which shrinks lists holding long runs of consecutive indices (e.g. participation),
+// at the cost of a byte for every isolated zero gap.
+// This method will return an error if indices are negative, unsorted or duplicated.
+func EncodeIndices(indices []int, packZeroRuns bool) ([]byte, error) {
+	if err := checkIndices(^uint64(0), indices); err != nil {
+		return nil, err
+	}
+
+	flag := indicesPlain
+	if packZeroRuns {
+		flag = indicesZeroRuns
+	}
+	buf := make([]byte, 0, 1+binary.MaxVarintLen64+len(indices))
+	buf = append(buf, flag)
+	buf = appendUvarint(buf, uint64(len(indices)))
+	var expected uint64
+	for i := 0; i < len(indices); i++ {
+		gap := uint64(indices[i]) - expected
+		if gap == 0 && packZeroRuns {
+			run := 1
+			for i+run < len(indices) && indices[i+run] == indices[i+run-1]+1 {
+				run++
+			}
+			buf = append(buf, 0)
+			buf = appendUvarint(buf, uint64(run))
+			i += run - 1
+		} else {
+			buf = appendUvarint(buf, gap)
+		}
+		expected = uint64(indices[i]) + 1
+	}
+	return buf, nil
+}
+
+// DecodeIndices decodes a list of indices encoded by EncodeIndices, which must all be below `n`.
+// Bounding indices by the size of the bitlist also bounds the memory used for untrusted input.
+// This method will return an error if the encoding is malformed, or holds an index out of range.
+func DecodeIndices(data []byte, n uint64) ([]int, error) {
+	if len(data) == 0 || data[0] > indicesZeroRuns {
+		return nil, ErrInvalidIndexEncoding
+	}
+	packed := data[0] == indicesZeroRuns
+	data = data[1:]
+	count, m := binary.Uvarint(data)
+	// Without packing, every index takes at least a byte.
+	if m <= 0 || count > n || (!packed && count > uint64(len(data)-m)) {
+		return nil, ErrInvalidIndexEncoding
+	}
+	data = data[m:]
+
+	capacity := len(data)
+	if count < uint64(capacity) {
+		capacity = int(count)
+	}
+	indices := make([]int, 0, capacity)
+	var expected uint64
+	for uint64(len(indices)) < count {
+		gap, m := binary.Uvarint(data)
+		if m <= 0 {
+			return nil, ErrInvalidIndexEncoding
+		}
+		data = data[m:]
+
+		run := uint64(1)
+		if gap == 0 && packed {
+			run, m = binary.Uvarint(data)
+			if m <= 0 || run == 0 || run > count-uint64(len(indices)) {
+				return nil, ErrInvalidIndexEncoding
+			}
+			data = data[m:]
+		}
+		if gap >= n-expected || run > n-expected-gap {
+			return nil, ErrInvalidIndexEncoding
+		}
+		for idx := expected + gap; idx < expected+gap+run; idx++ {
+			indices = append(indices, int(idx))
+		}
+		expected += gap + run
+	}
+	if len(data) != 0 {
+		return nil, ErrInvalidIndexEncoding
+	}
+	return indices, nil
+}
+
+// checkIndices returns an error unless indices are sorted, unique and below `n`.
+func checkIndices(n uint64, indices []int) error {
+	for i, idx := range indices {
+		if idx < 0 || uint64(idx) >= n || (i > 0 && idx <= indices[i-1]) {
+			return ErrInvalidIndices
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeIndices(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 100, 10000} {
+		for _, density := range []int{0, 1, 10, 50, 90, 100} {
+			for _, packZeroRuns := range []bool{false, true} {
+				t.Run(fmt.Sprintf("size:%d,density:%d,packed:%t", n, density, packZeroRuns), func(t *testing.T) {
+					b := NewBitlist64(n)
+					randomBits(r, b, density)
+					want := b.BitIndices()
+
+					data, err := EncodeIndices(want, packZeroRuns)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got, err := DecodeIndices(data, n)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(got) == 0 && len(want) == 0 {
+						return
+					}
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("DecodeIndices() = %v, wanted %v", got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestEncodeIndices_Size(t *testing.T) {
+	tests := []struct {
+		name         string
+		indices      []int
+		packZeroRuns bool
+		want         []byte
+	}{
+		{name: "empty", indices: []int{}, want: []byte{0x00, 0x00}},
+		{name: "empty packed", indices: nil, packZeroRuns: true, want: []byte{0x01, 0x00}},
+		{name: "gaps", indices: []int{3, 5, 200}, want: []byte{0x00, 0x03, 0x03, 0x01, 0xC2, 0x01}},
+		{name: "consecutive", indices: []int{0, 1, 2, 3, 10}, want: []byte{0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x06}},
+		{name: "consecutive packed", indices: []int{0, 1, 2, 3, 10}, packZeroRuns: true, want: []byte{0x01, 0x05, 0x00, 0x04, 0x06}},
+		{name: "isolated zero gap packed", indices: []int{1, 2, 4}, packZeroRuns: true, want: []byte{0x01, 0x03, 0x01, 0x00, 0x01, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeIndices(tt.indices, tt.packZeroRuns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodeIndices() = %#x, wanted %#x", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("long runs", func(t *testing.T) {
+		indices := make([]int, 100000)
+		for i := range indices {
+			indices[i] = i + 5
+		}
+		plain, err := EncodeIndices(indices, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packed, err := EncodeIndices(indices, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(packed) >= 16 || len(plain) < len(indices) {
+			t.Errorf("EncodeIndices() sizes = %d plain, %d packed, wanted packing to shrink runs", len(plain), len(packed))
+		}
+	})
+}
+
+func TestEncodeIndices_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		indices []int
+	}{
+		{name: "negative", indices: []int{-1}},
+		{name: "unsorted", indices: []int{5, 3}},
+		{name: "duplicate", indices: []int{3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, packZeroRuns := range []bool{false, true} {
+				if _, err := EncodeIndices(tt.indices, packZeroRuns); err != ErrInvalidIndices {
+					t.Errorf("EncodeIndices() error = %v, wanted %v", err, ErrInvalidIndices)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeIndices_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		n    uint64
+	}{
+		{name: "empty", data: []byte{}, n: 10},
+		{name: "unknown flag", data: []byte{0x02, 0x00}, n: 10},
+		{name: "missing count", data: []byte{0x00}, n: 10},
+		{name: "malformed count", data: []byte{0x00, 0xFF}, n: 10},
+		{name: "count above length", data: []byte{0x01, 0x0B, 0x00, 0x0B}, n: 10},
+		{name: "count above data", data: []byte{0x00, 0x03, 0x01, 0x01}, n: 10},
+		{name: "missing index", data: []byte{0x01, 0x02, 0x01}, n: 10},
+		{name: "index out of range", data: []byte{0x00, 0x01, 0x0A}, n: 10},
+		{name: "last index out of range", data: []byte{0x00, 0x02, 0x05, 0x04}, n: 10},
+		{name: "trailing data", data: []byte{0x00, 0x01, 0x01, 0x00}, n: 10},
+		{name: "empty run", data: []byte{0x01, 0x01, 0x00, 0x00}, n: 10},
+		{name: "missing run", data: []byte{0x01, 0x01, 0x00}, n: 10},
+		{name: "run above count", data: []byte{0x01, 0x02, 0x00, 0x03}, n: 10},
+		{name: "run out of range", data: []byte{0x01, 0x03, 0x08, 0x00, 0x02}, n: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeIndices(tt.data, tt.n); err != ErrInvalidIndexEncoding {
+				t.Errorf("DecodeIndices() error = %v, wanted %v", err, ErrInvalidIndexEncoding)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package bitfield
+
+import "math/big"
+
+// ToBools returns bits of any bitfield as an array of booleans, where element i is the value of
+// BitAt(i).
+func ToBools(b Bitfield) []bool {
+	data, n := bitBytes(b)
+	ret := make([]bool, n)
+	for i := range ret {
+		ret[i] = data[i>>3]&(1<<(uint(i)%8)) != 0
+	}
+	return ret
+}
+
+// ToBigInt returns bits of any bitfield as a non-negative integer, in the bit order of BitAt i.e.
+// bit i of the integer is the value of BitAt(i).
+func ToBigInt(b Bitfield) *big.Int {
+	data, n := bitBytes(b)
+	numBytes := int((n + 7) >> 3)
+	buf := make([]byte, numBytes)
+	for i := 0; i < numBytes; i++ {
+		buf[numBytes-1-i] = data[i]
+	}
+	if numBytes > 0 {
+		buf[0] &= lastByteMask(n)
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+// boolsToBytes packs an array of booleans into bytes, in the bit order of BitAt.
+func boolsToBytes(bools []bool) []byte {
+	ret := make([]byte, (len(bools)+7)>>3)
+	for i, val := range bools {
+		if val {
+			ret[i>>3] |= 1 << (uint(i) % 8)
+		}
+	}
+	return ret
+}
+
+// bigIntToBytes returns bits of a non-negative integer as little-endian bytes, holding `n` bits.
+// This method will return an error if the integer is negative or does not fit into `n` bits.
+func bigIntToBytes(n uint64, x *big.Int) ([]byte, error) {
+	if x.Sign() < 0 || uint64(x.BitLen()) > n {
+		return nil, ErrBigIntOutOfRange
+	}
+	buf := x.FillBytes(make([]byte, (n+7)>>3))
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return buf, nil
+}
+
+// bitfieldWords returns a copy of bits of any bitfield as words, with bits at or above the length
+// cleared.
+func bitfieldWords(b Bitfield) []uint64 {
+	data, n := bitWords(b)
+	ret := make([]uint64, numWordsRequired(n))
+	copy(ret, data)
+	if len(ret) > 0 {
+		ret[len(ret)-1] &= lastWordMask(n)
+	}
+	return ret
+}
+
+// bytesToBitlist returns a bitlist of size `n` holding given bits, which must all be below `n`.
+func bytesToBitlist(n uint64, data []byte) Bitlist {
+	ret := NewBitlist(n)
+	for i, bt := range data {
+		ret[i] |= bt
+	}
+	return ret
+}
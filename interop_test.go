@@ -0,0 +1,189 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestToBools(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	type conversion struct {
+		b         Bitfield
+		toBools   func() []bool
+		toBigInt  func() *big.Int
+		words     func() []uint64
+		fromBools func([]bool) (Bitfield, error)
+		fromInt   func(*big.Int) (Bitfield, error)
+	}
+	newConversions := []func() conversion{
+		func() conversion {
+			b := NewBitlist(uint64(r.Intn(200)))
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitlistFromBools(v), nil },
+				func(x *big.Int) (Bitfield, error) { return NewBitlistFromBigInt(b.Len(), x) }}
+		},
+		func() conversion {
+			b := NewBitlist64(uint64(r.Intn(200)))
+			return conversion{b, b.ToBools, b.ToBigInt, nil,
+				func(v []bool) (Bitfield, error) { return NewBitlist64FromBools(v), nil },
+				func(x *big.Int) (Bitfield, error) { return NewBitlist64FromBigInt(b.Len(), x) }}
+		},
+		func() conversion {
+			b := NewBitvector4()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector4FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector4FromBigInt(x) }}
+		},
+		func() conversion {
+			b := NewBitvector8()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector8FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector8FromBigInt(x) }}
+		},
+		func() conversion {
+			b := NewBitvector16()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector16FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector16FromBigInt(x) }}
+		},
+		func() conversion {
+			b := NewBitvector32()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector32FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector32FromBigInt(x) }}
+		},
+		func() conversion {
+			b := NewBitvector64()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector64FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector64FromBigInt(x) }}
+		},
+		func() conversion {
+			b := NewBitvector128()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector128FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector128FromBigInt(x) }}
+		},
+		func() conversion {
+			b := NewBitvector256()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector256FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector256FromBigInt(x) }}
+		},
+		func() conversion {
+			b := NewBitvector512()
+			return conversion{b, b.ToBools, b.ToBigInt, b.Words,
+				func(v []bool) (Bitfield, error) { return NewBitvector512FromBools(v) },
+				func(x *big.Int) (Bitfield, error) { return NewBitvector512FromBigInt(x) }}
+		},
+	}
+	for i := 0; i < 10; i++ {
+		for _, newConversion := range newConversions {
+			c := newConversion()
+			randomBits(r, c.b, r.Intn(101))
+			t.Run(fmt.Sprintf("%T,size:%d", c.b, c.b.Len()), func(t *testing.T) {
+				wantBools := make([]bool, c.b.Len())
+				wantInt := new(big.Int)
+				wantWords := make([]uint64, numWordsRequired(c.b.Len()))
+				for i := range wantBools {
+					wantBools[i] = c.b.BitAt(uint64(i))
+					if wantBools[i] {
+						wantInt.SetBit(wantInt, i, 1)
+						wantWords[i/64] |= 1 << (uint(i) % 64)
+					}
+				}
+
+				bools := c.toBools()
+				if !reflect.DeepEqual(bools, wantBools) {
+					t.Errorf("ToBools() = %v, wanted %v", bools, wantBools)
+				}
+				x := c.toBigInt()
+				if x.Cmp(wantInt) != 0 {
+					t.Errorf("ToBigInt() = %s, wanted %s", x, wantInt)
+				}
+				if c.words != nil {
+					if words := c.words(); !reflect.DeepEqual(words, wantWords) {
+						t.Errorf("Words() = %#x, wanted %#x", words, wantWords)
+					}
+				}
+
+				got, err := c.fromBools(bools)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", c.b) || !Equal(got, c.b) {
+					t.Errorf("FromBools() = %v, wanted %v", got.BitIndices(), c.b.BitIndices())
+				}
+				got, err = c.fromInt(x)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", c.b) || !Equal(got, c.b) {
+					t.Errorf("FromBigInt() = %v, wanted %v", got.BitIndices(), c.b.BitIndices())
+				}
+
+				if _, err := c.fromInt(new(big.Int).Lsh(big.NewInt(1), uint(c.b.Len()))); err != ErrBigIntOutOfRange {
+					t.Errorf("FromBigInt() of 2^%d error = %v, wanted %v", c.b.Len(), err, ErrBigIntOutOfRange)
+				}
+				if _, err := c.fromInt(big.NewInt(-1)); err != ErrBigIntOutOfRange {
+					t.Errorf("FromBigInt() of -1 error = %v, wanted %v", err, ErrBigIntOutOfRange)
+				}
+			})
+		}
+	}
+}
+
+func TestToBools_BitsAboveLength(t *testing.T) {
+	// Bits at or above the length must not leak into conversions.
+	b := &Bitlist64{size: 4, data: []uint64{0xF5}}
+	if want := []bool{true, false, true, false}; !reflect.DeepEqual(b.ToBools(), want) {
+		t.Errorf("ToBools() = %v, wanted %v", b.ToBools(), want)
+	}
+	if want := big.NewInt(5); b.ToBigInt().Cmp(want) != 0 {
+		t.Errorf("ToBigInt() = %s, wanted %s", b.ToBigInt(), want)
+	}
+
+	v := Bitvector4{0xF5}
+	if want := big.NewInt(5); v.ToBigInt().Cmp(want) != 0 {
+		t.Errorf("ToBigInt() = %s, wanted %s", v.ToBigInt(), want)
+	}
+	if want := []uint64{5}; !reflect.DeepEqual(v.Words(), want) {
+		t.Errorf("Words() = %#x, wanted %#x", v.Words(), want)
+	}
+
+	// The length bit of a bitlist of 64 bits is in a word of its own.
+	l := NewBitlist(64)
+	l.SetBitAt(63, true)
+	if want := []uint64{1 << 63}; !reflect.DeepEqual(l.Words(), want) {
+		t.Errorf("Words() = %#x, wanted %#x", l.Words(), want)
+	}
+}
+
+func TestNewBitvectorFromBools_WrongLen(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func([]bool) error
+		size int
+	}{
+		{name: "Bitvector4", f: func(v []bool) error { _, err := NewBitvector4FromBools(v); return err }, size: 4},
+		{name: "Bitvector8", f: func(v []bool) error { _, err := NewBitvector8FromBools(v); return err }, size: 8},
+		{name: "Bitvector16", f: func(v []bool) error { _, err := NewBitvector16FromBools(v); return err }, size: 16},
+		{name: "Bitvector32", f: func(v []bool) error { _, err := NewBitvector32FromBools(v); return err }, size: 32},
+		{name: "Bitvector64", f: func(v []bool) error { _, err := NewBitvector64FromBools(v); return err }, size: 64},
+		{name: "Bitvector128", f: func(v []bool) error { _, err := NewBitvector128FromBools(v); return err }, size: 128},
+		{name: "Bitvector256", f: func(v []bool) error { _, err := NewBitvector256FromBools(v); return err }, size: 256},
+		{name: "Bitvector512", f: func(v []bool) error { _, err := NewBitvector512FromBools(v); return err }, size: 512},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, n := range []int{0, tt.size - 1, tt.size + 1} {
+				if err := tt.f(make([]bool, n)); err != ErrWrongLen {
+					t.Errorf("FromBools() of %d values error = %v, wanted %v", n, err, ErrWrongLen)
+				}
+			}
+		})
+	}
+}
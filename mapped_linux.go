@@ -0,0 +1,190 @@
+//go:build linux
+// +build linux
+
+package bitfield
+
+import (
+	"hash/crc32"
+	"os"
+	"reflect"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// mappedMagic identifies files holding a mapped bitlist. The header is stored in the native
+	// byte order, so a file written on a machine of a different endianness fails this check.
+	mappedMagic = uint64(0x343654534c544942) // "BITLST64" in little-endian.
+	// mappedHeaderWords is the number of words in the file header: magic, size, checksum and a
+	// reserved word, keeping bits of the bitlist word aligned.
+	mappedHeaderWords = 4
+	// mappedHeaderSize is the size of the file header in bytes.
+	mappedHeaderSize = mappedHeaderWords * bytesInWord
+)
+
+// MappedBitlist64 is a Bitlist64 backed by a memory-mapped file, so that bitlists too large to be
+// loaded at startup can be used directly from disk, and persist across restarts. The embedded
+// Bitlist64 operates directly on the mapped words, so modifications are written to the file.
+//
+// The file starts with a header recording the size of the bitlist and a CRC-32C checksum of its
+// bits. The checksum is updated by Sync() and Close(), and verified when the file is opened.
+//
+// In read-only mode the file is mapped privately, so the bitlist may still be modified, but changes
+// are only kept in memory and are never written to the file.
+type MappedBitlist64 struct {
+	*Bitlist64
+	file     *os.File
+	mem      []byte
+	header   []uint64
+	readOnly bool
+}
+
+// CreateMappedBitlist64 creates a file at a given path holding a new bitlist of size `n`, and maps
+// it in read-write mode. An existing file is truncated.
+func CreateMappedBitlist64(path string, n uint64) (*MappedBitlist64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(mappedHeaderSize + numWordsRequired(n)*bytesInWord)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	m, err := mapBitlist(f, false)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	m.header[0] = mappedMagic
+	m.header[1] = n
+	m.Bitlist64.size = n
+	if err := m.Sync(); err != nil {
+		_ = m.unmap()
+		return nil, err
+	}
+	return m, nil
+}
+
+// OpenMappedBitlist64 maps a file at a given path created by CreateMappedBitlist64.
+// This method will return an error if the file does not hold a mapped bitlist, or its checksum
+// does not match.
+func OpenMappedBitlist64(path string, readOnly bool) (*MappedBitlist64, error) {
+	flag := os.O_RDWR
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+	m, err := mapBitlist(f, readOnly)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	size := m.header[1]
+	if m.header[0] != mappedMagic || uint64(len(m.Bitlist64.data)) != uint64(numWordsRequired(size)) {
+		_ = m.unmap()
+		return nil, ErrInvalidMappedFile
+	}
+	m.Bitlist64.size = size
+	if m.header[2] != uint64(m.checksum()) {
+		_ = m.unmap()
+		return nil, ErrChecksumMismatch
+	}
+	return m, nil
+}
+
+// ReadOnly returns true if the file is mapped in read-only mode.
+func (m *MappedBitlist64) ReadOnly() bool {
+	return m.readOnly
+}
+
+// Sync updates the checksum in the header and flushes all changes to the file.
+// This method will return an error if the file is mapped in read-only mode.
+func (m *MappedBitlist64) Sync() error {
+	if m.readOnly {
+		return ErrReadOnly
+	}
+	m.header[2] = uint64(m.checksum())
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&m.mem[0])), uintptr(len(m.mem)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close flushes all changes to the file, unless it is mapped in read-only mode, and unmaps it.
+// The bitlist must not be used after it is closed.
+func (m *MappedBitlist64) Close() error {
+	if m.mem == nil {
+		return nil
+	}
+	var err error
+	if !m.readOnly {
+		err = m.Sync()
+	}
+	if unmapErr := m.unmap(); err == nil {
+		err = unmapErr
+	}
+	return err
+}
+
+// mapBitlist maps the whole file, splitting the mapping into the header and words of the bitlist.
+func mapBitlist(f *os.File, readOnly bool) (*MappedBitlist64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < mappedHeaderSize || info.Size()%bytesInWord != 0 {
+		return nil, ErrInvalidMappedFile
+	}
+
+	// A read-only file is mapped copy-on-write, so that writing to the bitlist does not fault.
+	flags := syscall.MAP_SHARED
+	if readOnly {
+		flags = syscall.MAP_PRIVATE
+	}
+	mem, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ|syscall.PROT_WRITE, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// The mapping is page aligned, so it can be accessed as words.
+	var words []uint64
+	hdr := (*reflect.SliceHeader)(unsafe.Pointer(&words))
+	hdr.Data = uintptr(unsafe.Pointer(&mem[0]))
+	hdr.Len = len(mem) >> bytesInWordLog2
+	hdr.Cap = hdr.Len
+
+	return &MappedBitlist64{
+		Bitlist64: &Bitlist64{
+			data: words[mappedHeaderWords:],
+		},
+		file:     f,
+		mem:      mem,
+		header:   words[:mappedHeaderWords],
+		readOnly: readOnly,
+	}, nil
+}
+
+// checksum returns the CRC-32C checksum of bits of the bitlist.
+func (m *MappedBitlist64) checksum() uint32 {
+	return crc32.Checksum(m.mem[mappedHeaderSize:], castagnoliTable)
+}
+
+// unmap unmaps and closes the file. Slices pointing into the mapping are cleared, so that using
+// the bitlist afterwards does not access unmapped memory.
+func (m *MappedBitlist64) unmap() error {
+	m.Bitlist64.size = 0
+	m.Bitlist64.data = nil
+	m.header = nil
+	err := syscall.Munmap(m.mem)
+	m.mem = nil
+	if closeErr := m.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+//go:build linux
+// +build linux
+
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMappedBitlist64(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 64, 65, 1000, 100000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bitlist")
+			m, err := CreateMappedBitlist64(path, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Len() != n || m.Count() != 0 {
+				t.Fatalf("Len() = %d, Count() = %d, wanted %d, 0", m.Len(), m.Count(), n)
+			}
+			want := NewBitlist64(n)
+			randomBits(rand.New(rand.NewSource(int64(n))), m, 50)
+			randomBits(rand.New(rand.NewSource(int64(n))), want, 50)
+			// Operations writing into the bitlist must work on the mapped words.
+			other := NewBitlist64(n)
+			randomBits(r, other, 10)
+			if err := m.NoAllocOr(other, m.Bitlist64); err != nil {
+				t.Fatal(err)
+			}
+			if err := want.NoAllocOr(other, want); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wantSize := int64(mappedHeaderSize + numWordsRequired(n)*bytesInWord); info.Size() != wantSize {
+				t.Errorf("file size = %d, wanted %d", info.Size(), wantSize)
+			}
+
+			for _, readOnly := range []bool{true, false} {
+				m, err = OpenMappedBitlist64(path, readOnly)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m.ReadOnly() != readOnly {
+					t.Errorf("ReadOnly() = %t, wanted %t", m.ReadOnly(), readOnly)
+				}
+				if !m.Equal(want) {
+					t.Errorf("reopened bitlist = %v, wanted %v", m.Bitlist64, want)
+				}
+				if !reflect.DeepEqual(m.BitIndices(), want.BitIndices()) {
+					t.Errorf("BitIndices() = %v, wanted %v", m.BitIndices(), want.BitIndices())
+				}
+				if readOnly {
+					if err := m.Sync(); err != ErrReadOnly {
+						t.Errorf("Sync() error = %v, wanted %v", err, ErrReadOnly)
+					}
+					// Modifications are allowed, but never reach the file.
+					m.NoAllocNot(m.Bitlist64)
+					if n > 0 {
+						m.SetBitAt(0, !m.BitAt(0))
+					}
+					if err := m.NoAllocOr(other, m.Bitlist64); err != nil {
+						t.Fatal(err)
+					}
+				} else if n > 0 {
+					// Modifications made after reopening are persisted too.
+					m.SetBitAt(n-1, !m.BitAt(n-1))
+					want.SetBitAt(n-1, !want.BitAt(n-1))
+					if err := m.Sync(); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := m.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if m.Len() != 0 || m.BitAt(0) {
+					t.Error("bitlist is still accessible after Close()")
+				}
+				if err := m.Close(); err != nil {
+					t.Errorf("second Close() error = %v", err)
+				}
+			}
+
+			m, err = OpenMappedBitlist64(path, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if err := m.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+			if !m.Equal(want) {
+				t.Errorf("reopened bitlist = %v, wanted %v", m.Bitlist64, want)
+			}
+		})
+	}
+}
+
+func TestOpenMappedBitlist64_Errors(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid")
+	m, err := CreateMappedBitlist64(valid, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetBitAt(3, true)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := func(f func(b []byte) []byte) []byte {
+		b := append([]byte(nil), content...)
+		return f(b)
+	}
+	tests := []struct {
+		name    string
+		content []byte
+		want    error
+	}{
+		{name: "empty", content: []byte{}, want: ErrInvalidMappedFile},
+		{name: "truncated header", content: content[:mappedHeaderSize-8], want: ErrInvalidMappedFile},
+		{name: "unaligned", content: content[:len(content)-1], want: ErrInvalidMappedFile},
+		{name: "missing words", content: content[:len(content)-8], want: ErrInvalidMappedFile},
+		{name: "bad magic", content: corrupt(func(b []byte) []byte { b[0] ^= 0xFF; return b }), want: ErrInvalidMappedFile},
+		{name: "bad size", content: corrupt(func(b []byte) []byte { b[8] = 200; return b }), want: ErrInvalidMappedFile},
+		{name: "flipped bit", content: corrupt(func(b []byte) []byte { b[mappedHeaderSize] ^= 0x01; return b }), want: ErrChecksumMismatch},
+		{name: "bad checksum", content: corrupt(func(b []byte) []byte { b[16] ^= 0x01; return b }), want: ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := OpenMappedBitlist64(path, true); err != tt.want {
+				t.Errorf("OpenMappedBitlist64() error = %v, wanted %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := OpenMappedBitlist64(filepath.Join(dir, "missing"), false); !os.IsNotExist(err) {
+		t.Errorf("OpenMappedBitlist64() error = %v, wanted a missing file error", err)
+	}
+}
@@ -0,0 +1,113 @@
+package bitfield
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// bitsPerChunk is the number of bits packed into a single 32-byte SSZ chunk.
+	bitsPerChunk = 256
+	// wordsPerChunk is the number of words packed into a single 32-byte SSZ chunk.
+	wordsPerChunk = bitsPerChunk / 64
+	// maxMerkleDepth is the depth of the largest tree supported, enough for any uint64 limit.
+	maxMerkleDepth = 64
+)
+
+// zeroHashes holds roots of all-zero subtrees, zeroHashes[i] being the root of a tree of depth i.
+var zeroHashes = func() [maxMerkleDepth + 1][32]byte {
+	var ret [maxMerkleDepth + 1][32]byte
+	for i := 1; i <= maxMerkleDepth; i++ {
+		ret[i] = hashPair(ret[i-1], ret[i-1])
+	}
+	return ret
+}()
+
+// hashPair returns the root of a tree with given left and right subtree roots.
+func hashPair(a, b [32]byte) [32]byte {
+	var buf [64]byte
+	copy(buf[:32], a[:])
+	copy(buf[32:], b[:])
+	return sha256.Sum256(buf[:])
+}
+
+// bitlistDepth returns the depth of the SSZ merkle tree of a bitlist with a given limit of bits.
+func bitlistDepth(limit uint64) int {
+	chunks := limit/bitsPerChunk + boolToUint64(limit%bitsPerChunk != 0)
+	if chunks <= 1 {
+		return 0
+	}
+	return bits.Len64(chunks - 1)
+}
+
+// wordsChunk packs the words of a given SSZ chunk index into 32 bytes. Missing words are zero.
+func wordsChunk(data []uint64, idx int) [32]byte {
+	var chunk [32]byte
+	for i := 0; i < wordsPerChunk; i++ {
+		w := idx*wordsPerChunk + i
+		if w >= len(data) {
+			break
+		}
+		binary.LittleEndian.PutUint64(chunk[i*bytesInWord:], data[w])
+	}
+	return chunk
+}
+
+// merkleizeWords returns the root of a tree of a given depth, with words packed into its leaves.
+// Words that do not fit into the tree are ignored, so callers must make sure they are zero.
+func merkleizeWords(data []uint64, depth int) [32]byte {
+	numChunks := (len(data) + wordsPerChunk - 1) / wordsPerChunk
+	if depth < maxMerkleDepth && numChunks > 1<<uint(depth) {
+		numChunks = 1 << uint(depth)
+	}
+	if numChunks == 0 {
+		return zeroHashes[depth]
+	}
+	layer := make([][32]byte, numChunks)
+	for i := range layer {
+		layer[i] = wordsChunk(data, i)
+	}
+	return merkleizeLayer(layer, 0, depth)
+}
+
+// merkleizeLayer returns the root of a tree of a given depth, whose nodes at the level `level` are
+// given. Missing nodes are roots of all-zero subtrees. The layer is used as a scratch space.
+func merkleizeLayer(layer [][32]byte, level, depth int) [32]byte {
+	if len(layer) == 0 {
+		return zeroHashes[depth]
+	}
+	for ; level < depth; level++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, zeroHashes[level])
+		}
+		for i := 0; i < len(layer)/2; i++ {
+			layer[i] = hashPair(layer[2*i], layer[2*i+1])
+		}
+		layer = layer[:len(layer)/2]
+	}
+	return layer[0]
+}
+
+// mixInLength mixes a bitlist length into the root of its bits, as the SSZ spec requires.
+func mixInLength(root [32]byte, length uint64) [32]byte {
+	var lengthChunk [32]byte
+	binary.LittleEndian.PutUint64(lengthChunk[:], length)
+	return hashPair(root, lengthChunk)
+}
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// bitlistRoot returns the SSZ hash_tree_root of a bitlist of a given size and limit, with its bits
+// packed into words. Bits at or above size must be zero.
+func bitlistRoot(data []uint64, size, limit uint64) ([32]byte, error) {
+	if size > limit {
+		return [32]byte{}, ErrExceedsLimit
+	}
+	return mixInLength(merkleizeWords(data, bitlistDepth(limit)), size), nil
+}
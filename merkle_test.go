@@ -0,0 +1,133 @@
+package bitfield
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// specBitlistRoot computes hash_tree_root of a bitlist following the SSZ spec literally: pack the
+// bits, pad to a power of two number of chunks, hash pairs until one is left and mix in the length.
+func specBitlistRoot(b Bitfield, limit uint64) [32]byte {
+	packed := make([]byte, (b.Len()+7)/8)
+	for i := uint64(0); i < b.Len(); i++ {
+		if b.BitAt(i) {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	limitChunks := (limit + 255) / 256
+	width := uint64(1)
+	for width < limitChunks {
+		width *= 2
+	}
+	nodes := make([][32]byte, width)
+	for i := range nodes {
+		if i*32 < len(packed) {
+			copy(nodes[i][:], packed[i*32:])
+		}
+	}
+	for len(nodes) > 1 {
+		next := make([][32]byte, len(nodes)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(nodes[2*i][:], nodes[2*i+1][:]...))
+		}
+		nodes = next
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], b.Len())
+	return sha256.Sum256(append(nodes[0][:], length[:]...))
+}
+
+func TestBitlistRoot(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	tests := []struct {
+		size  uint64
+		limit uint64
+	}{
+		{size: 0, limit: 0},
+		{size: 0, limit: 1},
+		{size: 0, limit: 2048},
+		{size: 1, limit: 1},
+		{size: 5, limit: 256},
+		{size: 256, limit: 256},
+		{size: 100, limit: 257},
+		{size: 257, limit: 257},
+		{size: 1000, limit: 1024},
+		{size: 1000, limit: 2048},
+		{size: 3000, limit: 5000},
+		{size: 5000, limit: 5000},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("size:%d,limit:%d", tt.size, tt.limit), func(t *testing.T) {
+			b := NewBitlist64(tt.size)
+			randomBits(r, b, 50)
+			got, err := bitlistRoot(b.data, b.size, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := specBitlistRoot(b, tt.limit); got != want {
+				t.Errorf("bitlistRoot() = %#x, wanted %#x", got, want)
+			}
+		})
+	}
+
+	if _, err := bitlistRoot(nil, 10, 9); err != ErrExceedsLimit {
+		t.Errorf("bitlistRoot() error = %v, wanted %v", err, ErrExceedsLimit)
+	}
+}
+
+func TestBitlist_HashTreeRoot(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 8, 255, 256, 257, 1000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			b := NewBitlist(n)
+			randomBits(r, b, 50)
+			want := specBitlistRoot(b, 2048)
+
+			got, err := b.HashTreeRoot(2048)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("Bitlist.HashTreeRoot() = %#x, wanted %#x", got, want)
+			}
+
+			b64, err := b.ToBitlist64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err = b64.HashTreeRoot(2048)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("Bitlist64.HashTreeRoot() = %#x, wanted %#x", got, want)
+			}
+		})
+	}
+
+	t.Run("bits above size", func(t *testing.T) {
+		b := &Bitlist64{size: 65, data: []uint64{0x01, 0xFF}}
+		got, err := b.HashTreeRoot(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := specBitlistRoot(b, 256); got != want {
+			t.Errorf("HashTreeRoot() = %#x, wanted %#x", got, want)
+		}
+		if b.data[1] != 0xFF {
+			t.Error("HashTreeRoot() modified the bitlist")
+		}
+	})
+
+	t.Run("exceeds limit", func(t *testing.T) {
+		if _, err := NewBitlist(10).HashTreeRoot(9); err != ErrExceedsLimit {
+			t.Errorf("Bitlist.HashTreeRoot() error = %v, wanted %v", err, ErrExceedsLimit)
+		}
+		if _, err := NewBitlist64(10).HashTreeRoot(9); err != ErrExceedsLimit {
+			t.Errorf("Bitlist64.HashTreeRoot() error = %v, wanted %v", err, ErrExceedsLimit)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package bitfield
+
+var _ = Bitfield(&MerkleizedBitlist{})
+
+// MerkleizedBitlist wraps a bitlist together with a cache of its SSZ merkle tree. Modifications
+// made through the wrapper mark the 32-byte chunks they touch as dirty, so HashTreeRoot() only
+// rehashes the paths from dirty chunks to the root, instead of the whole tree.
+//
+// The wrapped bitlist must not be modified directly, as such modifications can not be tracked.
+type MerkleizedBitlist struct {
+	bits      *Bitlist64
+	limit     uint64
+	depth     int
+	numChunks int
+	// layers[i] holds nodes at the level i of the tree, leaves (level 0) are read from the bits.
+	layers [][][32]byte
+	dirty  *Bitlist64 // One bit per chunk.
+}
+
+// NewMerkleizedBitlist wraps a given bitlist, hashed as an SSZ Bitlist with a given limit of bits.
+// The bitlist is used in place, not copied. All chunks start dirty, so the first HashTreeRoot()
+// call hashes the whole tree.
+// This method will return an error if the bitlist is longer than the limit.
+func NewMerkleizedBitlist(b *Bitlist64, limit uint64) (*MerkleizedBitlist, error) {
+	if b.size > limit {
+		return nil, ErrExceedsLimit
+	}
+	b.clearUnusedBits()
+
+	depth := bitlistDepth(limit)
+	numChunks := int((b.size + bitsPerChunk - 1) / bitsPerChunk)
+	layers := make([][][32]byte, depth+1)
+	for i, n := 1, numChunks; i <= depth; i++ {
+		n = (n + 1) / 2
+		layers[i] = make([][32]byte, n)
+	}
+	dirty := NewBitlist64(uint64(numChunks))
+	dirty.NoAllocNot(dirty)
+
+	return &MerkleizedBitlist{
+		bits:      b,
+		limit:     limit,
+		depth:     depth,
+		numChunks: numChunks,
+		layers:    layers,
+		dirty:     dirty,
+	}, nil
+}
+
+// Bits returns the underlying bitlist. The bitlist is not copied, and must not be modified.
+func (m *MerkleizedBitlist) Bits() *Bitlist64 {
+	return m.bits
+}
+
+// Limit returns the maximum number of bits the bitlist is hashed with.
+func (m *MerkleizedBitlist) Limit() uint64 {
+	return m.limit
+}
+
+// BitAt returns the bit value at the given index. If the index requested
+// exceeds the number of bits in the bitlist, then this method returns false.
+func (m *MerkleizedBitlist) BitAt(idx uint64) bool {
+	return m.bits.BitAt(idx)
+}
+
+// SetBitAt will set the bit at the given index to the given value, marking its chunk as dirty. If
+// the index requested exceeds the number of bits in the bitlist, then this method does nothing.
+func (m *MerkleizedBitlist) SetBitAt(idx uint64, val bool) {
+	// Out of bounds, or nothing to change, do nothing.
+	if idx >= m.bits.size || m.bits.BitAt(idx) == val {
+		return
+	}
+	m.bits.SetBitAt(idx, val)
+	m.dirty.SetBitAt(idx/bitsPerChunk, true)
+}
+
+// SetRange will set all bits in the half-open range [lo, hi) to the given value, marking their
+// chunks as dirty. Bits of the range exceeding the number of bits in the bitlist are ignored.
+func (m *MerkleizedBitlist) SetRange(lo, hi uint64, val bool) {
+	if hi > m.bits.size {
+		hi = m.bits.size
+	}
+	if lo >= hi {
+		return
+	}
+
+	first, last := int(lo>>wordSizeLog2), int((hi-1)>>wordSizeLog2)
+	for i := first; i <= last; i++ {
+		mask := allBitsSet
+		if i == first {
+			mask &= allBitsSet << (lo % wordSize)
+		}
+		if i == last {
+			mask &= lastWordMask(hi)
+		}
+		word := m.bits.data[i]
+		if val {
+			word |= mask
+		} else {
+			word &^= mask
+		}
+		if word != m.bits.data[i] {
+			m.bits.data[i] = word
+			m.dirty.SetBitAt(uint64(i/wordsPerChunk), true)
+		}
+	}
+}
+
+// Or sets all bits that are set in a given bitlist, marking changed chunks as dirty.
+// This method will return an error if bitlists are not the same length.
+func (m *MerkleizedBitlist) Or(c *Bitlist64) error {
+	if m.bits.size != c.size {
+		return ErrBitlistDifferentLength
+	}
+
+	numWords := numWordsRequired(m.bits.size)
+	for i := 0; i < numWords; i++ {
+		word := c.data[i]
+		if i == numWords-1 {
+			word &= lastWordMask(m.bits.size)
+		}
+		if word&^m.bits.data[i] != 0 {
+			m.bits.data[i] |= word
+			m.dirty.SetBitAt(uint64(i/wordsPerChunk), true)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of bits in the bitlist.
+func (m *MerkleizedBitlist) Len() uint64 {
+	return m.bits.Len()
+}
+
+// Count returns the number of 1s in the bitlist.
+func (m *MerkleizedBitlist) Count() uint64 {
+	return m.bits.Count()
+}
+
+// Bytes returns bits of the bitlist as an array of bytes.
+// The leading zeros in the bitlist will be trimmed to the smallest byte length representation of
+// the bitlist. This may produce an empty byte slice if all bits were zero.
+func (m *MerkleizedBitlist) Bytes() []byte {
+	return m.bits.Bytes()
+}
+
+// BitIndices returns list of bit indexes of bitlist where value is set to true.
+func (m *MerkleizedBitlist) BitIndices() []int {
+	return m.bits.BitIndices()
+}
+
+// HashTreeRoot returns the SSZ hash_tree_root of the bitlist, rehashing only the nodes above the
+// chunks that were modified since the last call.
+func (m *MerkleizedBitlist) HashTreeRoot() [32]byte {
+	// Indices of dirty nodes at the current level, sorted.
+	dirty := m.dirty.BitIndices()
+	for level := 1; level <= m.depth && len(dirty) > 0; level++ {
+		// Parents of sorted nodes are sorted, so duplicates are always adjacent.
+		parents := dirty[:0]
+		for _, idx := range dirty {
+			if p := idx >> 1; len(parents) == 0 || parents[len(parents)-1] != p {
+				parents = append(parents, p)
+			}
+		}
+		for _, p := range parents {
+			m.layers[level][p] = hashPair(m.node(level-1, 2*p), m.node(level-1, 2*p+1))
+		}
+		dirty = parents
+	}
+	for i := range m.dirty.data {
+		m.dirty.data[i] = 0
+	}
+
+	return mixInLength(m.node(m.depth, 0), m.bits.size)
+}
+
+// node returns the node at the given level and index of the tree. Nodes to the right of the last
+// chunk are roots of all-zero subtrees.
+func (m *MerkleizedBitlist) node(level, idx int) [32]byte {
+	if idx<<uint(level) >= m.numChunks {
+		return zeroHashes[level]
+	}
+	if level == 0 {
+		return wordsChunk(m.bits.data, idx)
+	}
+	return m.layers[level][idx]
+}
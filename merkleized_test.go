@@ -0,0 +1,138 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestMerkleizedBitlist_HashTreeRoot(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	tests := []struct {
+		size  uint64
+		limit uint64
+	}{
+		{size: 0, limit: 0},
+		{size: 0, limit: 4096},
+		{size: 10, limit: 10},
+		{size: 200, limit: 256},
+		{size: 300, limit: 512},
+		{size: 1000, limit: 1 << 16},
+		{size: 5000, limit: 5000},
+		{size: 20000, limit: 1 << 20},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("size:%d,limit:%d", tt.size, tt.limit), func(t *testing.T) {
+			want := NewBitlist64(tt.size)
+			randomBits(r, want, 30)
+			m, err := NewMerkleizedBitlist(want.Clone(), tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check := func(op string) {
+				got := m.HashTreeRoot()
+				full, err := want.HashTreeRoot(tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != full {
+					t.Fatalf("HashTreeRoot() after %s = %#x, wanted %#x", op, got, full)
+				}
+				if !m.Bits().Equal(want) {
+					t.Fatalf("Bits() after %s = %v, wanted %v", op, m.Bits(), want)
+				}
+			}
+			check("construction")
+			if got := m.HashTreeRoot(); got != specBitlistRoot(want, tt.limit) {
+				t.Fatalf("HashTreeRoot() = %#x, wanted %#x", got, specBitlistRoot(want, tt.limit))
+			}
+
+			for i := 0; i < 50; i++ {
+				// Several modifications between recomputations, to have multiple dirty paths.
+				for j := 0; j < r.Intn(4)+1; j++ {
+					switch r.Intn(3) {
+					case 0:
+						idx := uint64(r.Int63n(int64(tt.size) + 2))
+						val := r.Intn(2) == 0
+						m.SetBitAt(idx, val)
+						want.SetBitAt(idx, val)
+					case 1:
+						lo := uint64(r.Int63n(int64(tt.size) + 2))
+						hi := lo + uint64(r.Intn(600))
+						val := r.Intn(2) == 0
+						m.SetRange(lo, hi, val)
+						for k := lo; k < hi; k++ {
+							want.SetBitAt(k, val)
+						}
+					case 2:
+						c := NewBitlist64(tt.size)
+						for k := 0; k < 5 && tt.size > 0; k++ {
+							c.SetBitAt(uint64(r.Int63n(int64(tt.size))), true)
+						}
+						if err := m.Or(c); err != nil {
+							t.Fatal(err)
+						}
+						want, _ = want.Or(c)
+					}
+				}
+				check(fmt.Sprintf("round %d", i))
+			}
+		})
+	}
+}
+
+func TestMerkleizedBitlist_Dirty(t *testing.T) {
+	m, err := NewMerkleizedBitlist(NewBitlist64(10*bitsPerChunk), 1<<16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.HashTreeRoot()
+	if m.dirty.Count() != 0 {
+		t.Fatalf("dirty chunks after HashTreeRoot() = %v, wanted none", m.dirty.BitIndices())
+	}
+
+	m.SetBitAt(3*bitsPerChunk+1, true)
+	m.SetBitAt(3*bitsPerChunk+2, false) // Unchanged.
+	m.SetRange(5*bitsPerChunk-1, 6*bitsPerChunk+1, true)
+	m.SetRange(0, bitsPerChunk, false) // Unchanged.
+	c := NewBitlist64(m.Len())
+	c.SetBitAt(9*bitsPerChunk, true)
+	c.SetBitAt(3*bitsPerChunk+1, true) // Already set.
+	if err := m.Or(c); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{3, 4, 5, 6, 9}; !reflect.DeepEqual(m.dirty.BitIndices(), want) {
+		t.Errorf("dirty chunks = %v, wanted %v", m.dirty.BitIndices(), want)
+	}
+
+	// Leaves of clean chunks must not be read, so corrupt one behind the wrapper's back.
+	m.bits.data[0] = 0xFF
+	got := m.HashTreeRoot()
+	m.bits.data[0] = 0
+	want, err := m.Bits().HashTreeRoot(1 << 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("HashTreeRoot() rehashed a clean chunk")
+	}
+}
+
+func TestMerkleizedBitlist_Errors(t *testing.T) {
+	if _, err := NewMerkleizedBitlist(NewBitlist64(10), 9); err != ErrExceedsLimit {
+		t.Errorf("NewMerkleizedBitlist() error = %v, wanted %v", err, ErrExceedsLimit)
+	}
+
+	m, err := NewMerkleizedBitlist(NewBitlist64(10), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Or(NewBitlist64(11)); err != ErrBitlistDifferentLength {
+		t.Errorf("Or() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+	}
+	if m.Limit() != 10 {
+		t.Errorf("Limit() = %d, wanted %d", m.Limit(), 10)
+	}
+}
@@ -0,0 +1,161 @@
+package bitfield
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// jaccard returns the Jaccard index given intersection and union sizes. The index of two empty
+// sets is defined to be 1, as they are identical.
+func jaccard(intersection, union uint64) float64 {
+	if union == 0 {
+		return 1
+	}
+	return float64(intersection) / float64(union)
+}
+
+// checkVectorLen makes sure that both bitvectors are backed by byte arrays of an expected size.
+func checkVectorLen(b, c []byte, byteSize int) error {
+	if len(b) != len(c) {
+		return ErrBitvectorDifferentLength
+	}
+	if len(b) != byteSize {
+		return ErrWrongLen
+	}
+	return nil
+}
+
+// xorCountBits calculates the number of differing bits in the first n bits of two byte arrays.
+// Bytes are read as little-endian words, only the trailing bytes are counted one at a time.
+func xorCountBits(a, b []byte, n uint64) uint64 {
+	var cnt int
+	full := int(n >> 3)
+	i := 0
+	for ; i+bytesInWord <= full; i += bytesInWord {
+		cnt += bits.OnesCount64(binary.LittleEndian.Uint64(a[i:]) ^ binary.LittleEndian.Uint64(b[i:]))
+	}
+	for ; i < full; i++ {
+		cnt += bits.OnesCount8(a[i] ^ b[i])
+	}
+	if n%8 != 0 {
+		cnt += bits.OnesCount8((a[full] ^ b[full]) & lastByteMask(n))
+	}
+	return uint64(cnt)
+}
+
+// andOrCountBits calculates sizes of an intersection and a union of the first n bits of two byte
+// arrays in a single pass.
+func andOrCountBits(a, b []byte, n uint64) (uint64, uint64) {
+	var and, or int
+	full := int(n >> 3)
+	i := 0
+	for ; i+bytesInWord <= full; i += bytesInWord {
+		x, y := binary.LittleEndian.Uint64(a[i:]), binary.LittleEndian.Uint64(b[i:])
+		and += bits.OnesCount64(x & y)
+		or += bits.OnesCount64(x | y)
+	}
+	for ; i < full; i++ {
+		and += bits.OnesCount8(a[i] & b[i])
+		or += bits.OnesCount8(a[i] | b[i])
+	}
+	if n%8 != 0 {
+		mask := lastByteMask(n)
+		and += bits.OnesCount8(a[full] & b[full] & mask)
+		or += bits.OnesCount8((a[full] | b[full]) & mask)
+	}
+	return uint64(and), uint64(or)
+}
+
+// isSubsetBits returns true if every bit set in the first n bits of a is also set in b.
+func isSubsetBits(a, b []byte, n uint64) bool {
+	full := int(n >> 3)
+	i := 0
+	for ; i+bytesInWord <= full; i += bytesInWord {
+		if binary.LittleEndian.Uint64(a[i:])&^binary.LittleEndian.Uint64(b[i:]) != 0 {
+			return false
+		}
+	}
+	for ; i < full; i++ {
+		if a[i]&^b[i] != 0 {
+			return false
+		}
+	}
+	return n%8 == 0 || (a[full]&^b[full])&lastByteMask(n) == 0
+}
+
+// isDisjointBits returns true if no bit is set in the first n bits of both a and b.
+func isDisjointBits(a, b []byte, n uint64) bool {
+	full := int(n >> 3)
+	i := 0
+	for ; i+bytesInWord <= full; i += bytesInWord {
+		if binary.LittleEndian.Uint64(a[i:])&binary.LittleEndian.Uint64(b[i:]) != 0 {
+			return false
+		}
+	}
+	for ; i < full; i++ {
+		if a[i]&b[i] != 0 {
+			return false
+		}
+	}
+	return n%8 == 0 || a[full]&b[full]&lastByteMask(n) == 0
+}
+
+// xorCountWords calculates the number of differing bits in the first n bits of two word arrays.
+func xorCountWords(a, b []uint64, n uint64) uint64 {
+	var cnt int
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords-1; i++ {
+		cnt += bits.OnesCount64(a[i] ^ b[i])
+	}
+	if numWords > 0 {
+		cnt += bits.OnesCount64((a[numWords-1] ^ b[numWords-1]) & lastWordMask(n))
+	}
+	return uint64(cnt)
+}
+
+// andOrCountWords calculates sizes of an intersection and a union of the first n bits of two
+// word arrays in a single pass.
+func andOrCountWords(a, b []uint64, n uint64) (uint64, uint64) {
+	var and, or int
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords-1; i++ {
+		and += bits.OnesCount64(a[i] & b[i])
+		or += bits.OnesCount64(a[i] | b[i])
+	}
+	if numWords > 0 {
+		mask := lastWordMask(n)
+		and += bits.OnesCount64(a[numWords-1] & b[numWords-1] & mask)
+		or += bits.OnesCount64((a[numWords-1] | b[numWords-1]) & mask)
+	}
+	return uint64(and), uint64(or)
+}
+
+// isSubsetWords returns true if every bit set in the first n bits of a is also set in b.
+func isSubsetWords(a, b []uint64, n uint64) bool {
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords; i++ {
+		diff := a[i] &^ b[i]
+		if i == numWords-1 {
+			diff &= lastWordMask(n)
+		}
+		if diff != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isDisjointWords returns true if no bit is set in the first n bits of both a and b.
+func isDisjointWords(a, b []uint64, n uint64) bool {
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords; i++ {
+		common := a[i] & b[i]
+		if i == numWords-1 {
+			common &= lastWordMask(n)
+		}
+		if common != 0 {
+			return false
+		}
+	}
+	return true
+}
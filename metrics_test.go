@@ -0,0 +1,353 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// metrics holds results of all similarity metrics for a pair of bitfields.
+type metrics struct {
+	hamming, intersection, union uint64
+	jaccard                      float64
+	subset, disjoint             bool
+}
+
+// naiveMetrics computes similarity metrics bit by bit, to serve as a reference.
+func naiveMetrics(a, b Bitfield) metrics {
+	var m metrics
+	m.subset, m.disjoint = true, true
+	for i := uint64(0); i < a.Len(); i++ {
+		x, y := a.BitAt(i), b.BitAt(i)
+		if x != y {
+			m.hamming++
+		}
+		if x && y {
+			m.intersection++
+			m.disjoint = false
+		}
+		if x || y {
+			m.union++
+		}
+		if x && !y {
+			m.subset = false
+		}
+	}
+	m.jaccard = jaccard(m.intersection, m.union)
+	return m
+}
+
+func randomBits(r *rand.Rand, b Bitfield, density int) {
+	for i := uint64(0); i < b.Len(); i++ {
+		b.SetBitAt(i, r.Intn(100) < density)
+	}
+}
+
+func TestBitlist_Metrics(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 7, 8, 9, 63, 64, 65, 127, 129, 200, 1024} {
+		for _, density := range []int{0, 5, 50, 100} {
+			t.Run(fmt.Sprintf("size:%d,density:%d", n, density), func(t *testing.T) {
+				a, b := NewBitlist(n), NewBitlist(n)
+				randomBits(r, a, density)
+				randomBits(r, b, density)
+				// Make sure the subset branch gets exercised.
+				if density == 5 {
+					b, _ = b.Or(a)
+				}
+				want := naiveMetrics(a, b)
+
+				got := metrics{}
+				var err error
+				if got.hamming, err = a.HammingDistance(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.intersection, err = a.IntersectionSize(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.union, err = a.UnionSize(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.jaccard, err = a.Jaccard(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.subset, err = a.IsSubsetOf(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.disjoint, err = a.IsDisjoint(b); err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("(%x) metrics (%x) = %+v, wanted %+v", a, b, got, want)
+				}
+			})
+		}
+	}
+
+	t.Run("check errors", func(t *testing.T) {
+		a, b := NewBitlist(8), NewBitlist(9)
+		if _, err := a.HammingDistance(b); err != ErrBitlistDifferentLength {
+			t.Errorf("HammingDistance() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.IntersectionSize(b); err != ErrBitlistDifferentLength {
+			t.Errorf("IntersectionSize() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.UnionSize(b); err != ErrBitlistDifferentLength {
+			t.Errorf("UnionSize() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.Jaccard(b); err != ErrBitlistDifferentLength {
+			t.Errorf("Jaccard() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.IsSubsetOf(b); err != ErrBitlistDifferentLength {
+			t.Errorf("IsSubsetOf() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.IsDisjoint(b); err != ErrBitlistDifferentLength {
+			t.Errorf("IsDisjoint() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+	})
+}
+
+func TestBitlist64_Metrics(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 7, 8, 9, 63, 64, 65, 200, 1024} {
+		for _, density := range []int{0, 5, 50, 100} {
+			t.Run(fmt.Sprintf("size:%d,density:%d", n, density), func(t *testing.T) {
+				a, b := NewBitlist64(n), NewBitlist64(n)
+				randomBits(r, a, density)
+				randomBits(r, b, density)
+				if density == 5 {
+					b, _ = b.Or(a)
+				}
+				want := naiveMetrics(a, b)
+
+				got := metrics{}
+				var err error
+				if got.hamming, err = a.HammingDistance(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.intersection, err = a.IntersectionSize(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.union, err = a.UnionSize(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.jaccard, err = a.Jaccard(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.subset, err = a.IsSubsetOf(b); err != nil {
+					t.Fatal(err)
+				}
+				if got.disjoint, err = a.IsDisjoint(b); err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("(%+v) metrics (%+v) = %+v, wanted %+v", a, b, got, want)
+				}
+			})
+		}
+	}
+
+	t.Run("bits above size are ignored", func(t *testing.T) {
+		a := &Bitlist64{size: 4, data: []uint64{0xF1}}
+		b := &Bitlist64{size: 4, data: []uint64{0x01}}
+		if got, err := a.HammingDistance(b); got != 0 || err != nil {
+			t.Errorf("HammingDistance() = %d, %v, wanted 0", got, err)
+		}
+		if got, err := a.IsSubsetOf(b); !got || err != nil {
+			t.Errorf("IsSubsetOf() = %t, %v, wanted true", got, err)
+		}
+	})
+
+	t.Run("check errors", func(t *testing.T) {
+		a, b := NewBitlist64(64), NewBitlist64(128)
+		if _, err := a.HammingDistance(b); err != ErrBitlistDifferentLength {
+			t.Errorf("HammingDistance() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.IntersectionSize(b); err != ErrBitlistDifferentLength {
+			t.Errorf("IntersectionSize() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.UnionSize(b); err != ErrBitlistDifferentLength {
+			t.Errorf("UnionSize() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.Jaccard(b); err != ErrBitlistDifferentLength {
+			t.Errorf("Jaccard() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.IsSubsetOf(b); err != ErrBitlistDifferentLength {
+			t.Errorf("IsSubsetOf() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := a.IsDisjoint(b); err != ErrBitlistDifferentLength {
+			t.Errorf("IsDisjoint() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+	})
+}
+
+func TestBitvector_Metrics(t *testing.T) {
+	type metricsFunc func(a, b Bitfield) (metrics, error)
+	// vectorMetrics adapts typed bitvector methods, so that every vector size can share the test.
+	vectorMetrics := func(
+		hamming, intersection, union func(a, b Bitfield) (uint64, error),
+		jac func(a, b Bitfield) (float64, error),
+		subset, disjoint func(a, b Bitfield) (bool, error),
+	) metricsFunc {
+		return func(a, b Bitfield) (metrics, error) {
+			var m metrics
+			var err error
+			if m.hamming, err = hamming(a, b); err != nil {
+				return m, err
+			}
+			if m.intersection, err = intersection(a, b); err != nil {
+				return m, err
+			}
+			if m.union, err = union(a, b); err != nil {
+				return m, err
+			}
+			if m.jaccard, err = jac(a, b); err != nil {
+				return m, err
+			}
+			if m.subset, err = subset(a, b); err != nil {
+				return m, err
+			}
+			m.disjoint, err = disjoint(a, b)
+			return m, err
+		}
+	}
+	tests := []struct {
+		name    string
+		new     func() Bitfield
+		metrics metricsFunc
+	}{
+		{
+			name: "Bitvector4",
+			new:  func() Bitfield { return NewBitvector4() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector4).HammingDistance(b.(Bitvector4)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector4).IntersectionSize(b.(Bitvector4)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector4).UnionSize(b.(Bitvector4)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector4).Jaccard(b.(Bitvector4)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector4).IsSubsetOf(b.(Bitvector4)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector4).IsDisjoint(b.(Bitvector4)) },
+			),
+		},
+		{
+			name: "Bitvector8",
+			new:  func() Bitfield { return NewBitvector8() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector8).HammingDistance(b.(Bitvector8)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector8).IntersectionSize(b.(Bitvector8)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector8).UnionSize(b.(Bitvector8)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector8).Jaccard(b.(Bitvector8)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector8).IsSubsetOf(b.(Bitvector8)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector8).IsDisjoint(b.(Bitvector8)) },
+			),
+		},
+		{
+			name: "Bitvector16",
+			new:  func() Bitfield { return NewBitvector16() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector16).HammingDistance(b.(Bitvector16)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector16).IntersectionSize(b.(Bitvector16)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector16).UnionSize(b.(Bitvector16)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector16).Jaccard(b.(Bitvector16)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector16).IsSubsetOf(b.(Bitvector16)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector16).IsDisjoint(b.(Bitvector16)) },
+			),
+		},
+		{
+			name: "Bitvector32",
+			new:  func() Bitfield { return NewBitvector32() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector32).HammingDistance(b.(Bitvector32)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector32).IntersectionSize(b.(Bitvector32)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector32).UnionSize(b.(Bitvector32)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector32).Jaccard(b.(Bitvector32)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector32).IsSubsetOf(b.(Bitvector32)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector32).IsDisjoint(b.(Bitvector32)) },
+			),
+		},
+		{
+			name: "Bitvector64",
+			new:  func() Bitfield { return NewBitvector64() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector64).HammingDistance(b.(Bitvector64)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector64).IntersectionSize(b.(Bitvector64)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector64).UnionSize(b.(Bitvector64)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector64).Jaccard(b.(Bitvector64)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector64).IsSubsetOf(b.(Bitvector64)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector64).IsDisjoint(b.(Bitvector64)) },
+			),
+		},
+		{
+			name: "Bitvector128",
+			new:  func() Bitfield { return NewBitvector128() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector128).HammingDistance(b.(Bitvector128)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector128).IntersectionSize(b.(Bitvector128)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector128).UnionSize(b.(Bitvector128)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector128).Jaccard(b.(Bitvector128)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector128).IsSubsetOf(b.(Bitvector128)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector128).IsDisjoint(b.(Bitvector128)) },
+			),
+		},
+		{
+			name: "Bitvector256",
+			new:  func() Bitfield { return NewBitvector256() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector256).HammingDistance(b.(Bitvector256)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector256).IntersectionSize(b.(Bitvector256)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector256).UnionSize(b.(Bitvector256)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector256).Jaccard(b.(Bitvector256)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector256).IsSubsetOf(b.(Bitvector256)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector256).IsDisjoint(b.(Bitvector256)) },
+			),
+		},
+		{
+			name: "Bitvector512",
+			new:  func() Bitfield { return NewBitvector512() },
+			metrics: vectorMetrics(
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector512).HammingDistance(b.(Bitvector512)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector512).IntersectionSize(b.(Bitvector512)) },
+				func(a, b Bitfield) (uint64, error) { return a.(Bitvector512).UnionSize(b.(Bitvector512)) },
+				func(a, b Bitfield) (float64, error) { return a.(Bitvector512).Jaccard(b.(Bitvector512)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector512).IsSubsetOf(b.(Bitvector512)) },
+				func(a, b Bitfield) (bool, error) { return a.(Bitvector512).IsDisjoint(b.(Bitvector512)) },
+			),
+		},
+	}
+
+	r := rand.New(rand.NewSource(42))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, density := range []int{0, 5, 50, 100} {
+				a, b := tt.new(), tt.new()
+				randomBits(r, a, density)
+				randomBits(r, b, density)
+				want := naiveMetrics(a, b)
+				got, err := tt.metrics(a, b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("density:%d (%x) metrics (%x) = %+v, wanted %+v", density, a, b, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("check errors", func(t *testing.T) {
+		if _, err := (Bitvector128{0x01}).HammingDistance(NewBitvector128()); err != ErrBitvectorDifferentLength {
+			t.Errorf("HammingDistance() error = %v, wanted %v", err, ErrBitvectorDifferentLength)
+		}
+		if _, err := (Bitvector128{0x01}).Jaccard(Bitvector128{0x01}); err != ErrWrongLen {
+			t.Errorf("Jaccard() error = %v, wanted %v", err, ErrWrongLen)
+		}
+		if _, err := (Bitvector4{}).IsSubsetOf(NewBitvector4()); err != ErrBitvectorDifferentLength {
+			t.Errorf("IsSubsetOf() error = %v, wanted %v", err, ErrBitvectorDifferentLength)
+		}
+	})
+
+	t.Run("bits above size are ignored", func(t *testing.T) {
+		if got, err := (Bitvector4{0xF1}).HammingDistance(Bitvector4{0x01}); got != 0 || err != nil {
+			t.Errorf("HammingDistance() = %d, %v, wanted 0", got, err)
+		}
+	})
+}
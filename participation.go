@@ -0,0 +1,132 @@
+package bitfield
+
+import (
+	"math/bits"
+)
+
+// maxParticipationFlags is the number of flags that fit into a single uint8.
+const maxParticipationFlags = 8
+
+// ParticipationFlags holds a set of flags per validator (e.g. timely source, target and head),
+// stored column-wise: every flag has its own Bitlist64 plane, with one bit per validator. This
+// allows flags to be counted or combined with other bitlists without converting the uint8 form.
+type ParticipationFlags struct {
+	size   uint64
+	planes []*Bitlist64
+}
+
+// NewParticipationFlags creates participation flags for `n` validators, with `numFlags` flags each.
+// This method will return an error if numFlags is zero or exceeds 8.
+func NewParticipationFlags(n uint64, numFlags uint8) (*ParticipationFlags, error) {
+	if numFlags == 0 || numFlags > maxParticipationFlags {
+		return nil, ErrInvalidFlag
+	}
+	planes := make([]*Bitlist64, numFlags)
+	for i := range planes {
+		planes[i] = NewBitlist64(n)
+	}
+	return &ParticipationFlags{
+		size:   n,
+		planes: planes,
+	}, nil
+}
+
+// NewParticipationFlagsFrom creates participation flags from a uint8 per validator, where flag f
+// is stored in bit f. Bits at or above numFlags are ignored.
+// This method will return an error if numFlags is zero or exceeds 8.
+func NewParticipationFlagsFrom(flags []uint8, numFlags uint8) (*ParticipationFlags, error) {
+	p, err := NewParticipationFlags(uint64(len(flags)), numFlags)
+	if err != nil {
+		return nil, err
+	}
+	for f, plane := range p.planes {
+		for idx, v := range flags {
+			plane.data[idx>>wordSizeLog2] |= uint64((v>>uint(f))&1) << (uint(idx) % uint(wordSize))
+		}
+	}
+	return p, nil
+}
+
+// ToUint8s converts participation flags into a uint8 per validator, where flag f is stored in bit f.
+func (p *ParticipationFlags) ToUint8s() []uint8 {
+	ret := make([]uint8, p.size)
+	for f, plane := range p.planes {
+		for idx, word := range plane.data {
+			for word != 0 {
+				i := idx<<wordSizeLog2 + bits.TrailingZeros64(word)
+				if i >= len(ret) {
+					break
+				}
+				ret[i] |= 1 << uint(f)
+				word &= word - 1
+			}
+		}
+	}
+	return ret
+}
+
+// Len returns the number of validators.
+func (p *ParticipationFlags) Len() uint64 {
+	return p.size
+}
+
+// NumFlags returns the number of flags per validator.
+func (p *ParticipationFlags) NumFlags() uint8 {
+	return uint8(len(p.planes))
+}
+
+// HasFlag returns true if the flag f is set for the validator at the given index. If either the
+// index or the flag exceeds the bounds, then this method returns false.
+func (p *ParticipationFlags) HasFlag(idx uint64, f uint8) bool {
+	if int(f) >= len(p.planes) {
+		return false
+	}
+	return p.planes[f].BitAt(idx)
+}
+
+// AddFlag sets the flag f for the validator at the given index. If either the index or the flag
+// exceeds the bounds, then this method does nothing.
+func (p *ParticipationFlags) AddFlag(idx uint64, f uint8) {
+	if int(f) >= len(p.planes) {
+		return
+	}
+	p.planes[f].SetBitAt(idx, true)
+}
+
+// RemoveFlag clears the flag f for the validator at the given index. If either the index or the
+// flag exceeds the bounds, then this method does nothing.
+func (p *ParticipationFlags) RemoveFlag(idx uint64, f uint8) {
+	if int(f) >= len(p.planes) {
+		return
+	}
+	p.planes[f].SetBitAt(idx, false)
+}
+
+// FlagBitlist returns the plane of flag f, with a bit set for every validator having the flag.
+// The bitlist is not copied, so modifying it modifies the participation flags. If the flag
+// exceeds the number of flags, then this method returns nil.
+func (p *ParticipationFlags) FlagBitlist(f uint8) *Bitlist64 {
+	if int(f) >= len(p.planes) {
+		return nil
+	}
+	return p.planes[f]
+}
+
+// CountFlag returns the number of validators having the flag f set. If the flag exceeds the
+// number of flags, then this method returns 0.
+func (p *ParticipationFlags) CountFlag(f uint8) uint64 {
+	if int(f) >= len(p.planes) {
+		return 0
+	}
+	return p.planes[f].Count()
+}
+
+// WeightedSum returns the sum of weights[i] over every validator i having the flag f set.
+// This method will return an error if the flag exceeds the number of flags, if the weights are
+// shorter than the number of validators, or if the sum overflows uint64.
+func (p *ParticipationFlags) WeightedSum(f uint8, weights []uint64) (uint64, error) {
+	if int(f) >= len(p.planes) {
+		return 0, ErrInvalidFlag
+	}
+	return p.planes[f].WeightedSum(weights)
+}
@@ -0,0 +1,126 @@
+package bitfield
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParticipationFlags_NewParticipationFlags(t *testing.T) {
+	for _, numFlags := range []uint8{0, 9} {
+		if _, err := NewParticipationFlags(10, numFlags); err != ErrInvalidFlag {
+			t.Errorf("NewParticipationFlags(10, %d) error = %v, wanted %v", numFlags, err, ErrInvalidFlag)
+		}
+		if _, err := NewParticipationFlagsFrom([]uint8{0x01}, numFlags); err != ErrInvalidFlag {
+			t.Errorf("NewParticipationFlagsFrom(_, %d) error = %v, wanted %v", numFlags, err, ErrInvalidFlag)
+		}
+	}
+
+	p, err := NewParticipationFlags(100, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Len() != 100 || p.NumFlags() != 3 {
+		t.Errorf("NewParticipationFlags(100, 3) = len:%d, flags:%d", p.Len(), p.NumFlags())
+	}
+	for f := uint8(0); f < 3; f++ {
+		if p.FlagBitlist(f).Len() != 100 || p.CountFlag(f) != 0 {
+			t.Errorf("FlagBitlist(%d) = %+v, wanted empty bitlist of size 100", f, p.FlagBitlist(f))
+		}
+	}
+}
+
+func TestParticipationFlags_Uint8s(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	flags := make([]uint8, 300)
+	for i := range flags {
+		flags[i] = uint8(r.Intn(8))
+	}
+	p, err := NewParticipationFlagsFrom(flags, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, v := range flags {
+		for f := uint8(0); f < 3; f++ {
+			if got, want := p.HasFlag(uint64(idx), f), v&(1<<f) != 0; got != want {
+				t.Errorf("HasFlag(%d, %d) = %t, wanted %t", idx, f, got, want)
+			}
+		}
+	}
+	if got := p.ToUint8s(); !reflect.DeepEqual(got, flags) {
+		t.Errorf("ToUint8s() = %v, wanted %v", got, flags)
+	}
+
+	t.Run("bits above flag count are ignored", func(t *testing.T) {
+		p, err := NewParticipationFlagsFrom([]uint8{0xFF, 0x08, 0x05}, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := p.ToUint8s(), []uint8{0x07, 0x00, 0x05}; !reflect.DeepEqual(got, want) {
+			t.Errorf("ToUint8s() = %v, wanted %v", got, want)
+		}
+	})
+}
+
+func TestParticipationFlags_AddFlag(t *testing.T) {
+	p, _ := NewParticipationFlags(10, 3)
+	p.AddFlag(1, 0)
+	p.AddFlag(1, 2)
+	p.AddFlag(9, 1)
+	p.AddFlag(10, 1) // Out of bounds.
+	p.AddFlag(2, 3)  // Out of bounds.
+
+	if got, want := p.ToUint8s(), []uint8{0, 5, 0, 0, 0, 0, 0, 0, 0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ToUint8s() = %v, wanted %v", got, want)
+	}
+	if p.HasFlag(2, 3) || p.HasFlag(10, 1) {
+		t.Error("HasFlag() = true for out of bounds access")
+	}
+
+	p.RemoveFlag(1, 0)
+	p.RemoveFlag(1, 5) // Out of bounds.
+	if p.HasFlag(1, 0) || !p.HasFlag(1, 2) {
+		t.Error("RemoveFlag() cleared wrong flag")
+	}
+}
+
+func TestParticipationFlags_FlagBitlist(t *testing.T) {
+	p, _ := NewParticipationFlags(70, 2)
+	p.AddFlag(69, 1)
+
+	plane := p.FlagBitlist(1)
+	if !plane.BitAt(69) {
+		t.Error("FlagBitlist(1).BitAt(69) = false, wanted true")
+	}
+	// Plane is not a copy.
+	plane.SetBitAt(3, true)
+	if !p.HasFlag(3, 1) {
+		t.Error("FlagBitlist() returned a copy")
+	}
+	if p.FlagBitlist(2) != nil {
+		t.Error("FlagBitlist(2) is expected to be nil")
+	}
+}
+
+func TestParticipationFlags_CountFlag(t *testing.T) {
+	flags := []uint8{0x01, 0x03, 0x07, 0x00, 0x06}
+	p, _ := NewParticipationFlagsFrom(flags, 3)
+	for f, want := range []uint64{3, 3, 2, 0} {
+		if got := p.CountFlag(uint8(f)); got != want {
+			t.Errorf("CountFlag(%d) = %d, wanted %d", f, got, want)
+		}
+	}
+
+	weights := []uint64{1, 10, 100, 1000, 10000}
+	for f, want := range []uint64{111, 10110, 10100} {
+		if got, err := p.WeightedSum(uint8(f), weights); got != want || err != nil {
+			t.Errorf("WeightedSum(%d) = %d, %v, wanted %d", f, got, err, want)
+		}
+	}
+	if _, err := p.WeightedSum(3, weights); err != ErrInvalidFlag {
+		t.Errorf("WeightedSum(3) error = %v, wanted %v", err, ErrInvalidFlag)
+	}
+	if _, err := p.WeightedSum(0, weights[:4]); err != ErrWeightsTooShort {
+		t.Errorf("WeightedSum(0) error = %v, wanted %v", err, ErrWeightsTooShort)
+	}
+}
@@ -0,0 +1,219 @@
+package bitfield
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Patch holds the bits that differ between two bitfields of the same length, so that one of them
+// can be reconstructed from the other e.g. to sync participation between nodes by sending only the
+// bits that changed. Flipped bits are stored as sorted half-open ranges [lo, hi), so long runs of
+// changes are as cheap as single bits.
+type Patch struct {
+	size uint64
+	// bounds holds lo, hi pairs of flipped ranges. Bounds are strictly increasing, so that ranges
+	// never overlap or touch.
+	bounds []uint64
+}
+
+// Diff returns a patch which turns bitfield `from` into bitfield `to` when applied.
+// This method will return an error if the bitfields are not the same length.
+func Diff(from, to Bitfield) (*Patch, error) {
+	a, n := bitWords(from)
+	b, m := bitWords(to)
+	if n != m {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	p := &Patch{size: n}
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords; i++ {
+		word := a[i] ^ b[i]
+		if i == numWords-1 {
+			word &= lastWordMask(n)
+		}
+		p.appendRuns(word, uint64(i)<<wordSizeLog2)
+	}
+	return p, nil
+}
+
+// Len returns the length of bitfields the patch applies to.
+func (p *Patch) Len() uint64 {
+	return p.size
+}
+
+// Count returns the number of bits flipped by the patch.
+func (p *Patch) Count() uint64 {
+	var c uint64
+	for i := 0; i < len(p.bounds); i += 2 {
+		c += p.bounds[i+1] - p.bounds[i]
+	}
+	return c
+}
+
+// Ranges returns the half-open ranges [lo, hi) of bits flipped by the patch, sorted.
+func (p *Patch) Ranges() [][2]uint64 {
+	ranges := make([][2]uint64, len(p.bounds)/2)
+	for i := range ranges {
+		ranges[i] = [2]uint64{p.bounds[2*i], p.bounds[2*i+1]}
+	}
+	return ranges
+}
+
+// Apply flips all bits of the patch in a given bitfield.
+// This method will return an error if the bitfield is not the length the patch was built for.
+func (p *Patch) Apply(b Bitfield) error {
+	if b.Len() != p.size {
+		return ErrBitlistDifferentLength
+	}
+
+	switch v := b.(type) {
+	case *Bitlist64:
+		for i := 0; i < len(p.bounds); i += 2 {
+			flipWordRange(v.data, p.bounds[i], p.bounds[i+1])
+		}
+	case Bitlist:
+		for i := 0; i < len(p.bounds); i += 2 {
+			for idx := p.bounds[i]; idx < p.bounds[i+1]; idx++ {
+				v[idx>>3] ^= 1 << (idx % 8)
+			}
+		}
+	default:
+		for i := 0; i < len(p.bounds); i += 2 {
+			for idx := p.bounds[i]; idx < p.bounds[i+1]; idx++ {
+				b.SetBitAt(idx, !b.BitAt(idx))
+			}
+		}
+	}
+	return nil
+}
+
+// Compose returns a single patch equivalent to applying the patch, followed by a given patch.
+// This method will return an error if the patches are not built for the same length.
+func (p *Patch) Compose(q *Patch) (*Patch, error) {
+	if p.size != q.size {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	// A bit is flipped by the composition if it is flipped by exactly one of the patches, so the
+	// result changes state at every bound of either patch, except for the bounds they share.
+	ret := &Patch{
+		size:   p.size,
+		bounds: make([]uint64, 0, len(p.bounds)+len(q.bounds)),
+	}
+	i, j := 0, 0
+	for i < len(p.bounds) || j < len(q.bounds) {
+		switch {
+		case j == len(q.bounds) || (i < len(p.bounds) && p.bounds[i] < q.bounds[j]):
+			ret.bounds = append(ret.bounds, p.bounds[i])
+			i++
+		case i == len(p.bounds) || q.bounds[j] < p.bounds[i]:
+			ret.bounds = append(ret.bounds, q.bounds[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return ret, nil
+}
+
+// Inverse returns a patch which undoes the patch. As patches flip bits, every patch is its own
+// inverse, so the returned patch is a copy.
+func (p *Patch) Inverse() *Patch {
+	return &Patch{
+		size:   p.size,
+		bounds: append([]uint64(nil), p.bounds...),
+	}
+}
+
+// MarshalBinary encodes the patch as uvarints: the length, the number of ranges, and then bounds
+// of all ranges, each as a delta from the previous one.
+func (p *Patch) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(p.bounds)*2)
+	buf = appendUvarint(buf, p.size)
+	buf = appendUvarint(buf, uint64(len(p.bounds)/2))
+	var prev uint64
+	for _, bound := range p.bounds {
+		buf = appendUvarint(buf, bound-prev)
+		prev = bound
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes the patch encoded by MarshalBinary.
+// This method will return an error if the encoding is malformed.
+func (p *Patch) UnmarshalBinary(data []byte) error {
+	size, n := binary.Uvarint(data)
+	if n <= 0 {
+		return ErrInvalidPatch
+	}
+	data = data[n:]
+	numRanges, n := binary.Uvarint(data)
+	// Every bound takes at least a byte, which also protects against huge allocations.
+	if n <= 0 || numRanges > uint64(len(data)-n)/2 {
+		return ErrInvalidPatch
+	}
+	data = data[n:]
+
+	bounds := make([]uint64, 2*numRanges)
+	var prev uint64
+	for i := range bounds {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 || (i > 0 && delta == 0) || delta > size-prev {
+			return ErrInvalidPatch
+		}
+		data = data[n:]
+		prev += delta
+		bounds[i] = prev
+	}
+	if len(data) != 0 {
+		return ErrInvalidPatch
+	}
+
+	p.size = size
+	p.bounds = bounds
+	return nil
+}
+
+// appendRuns appends runs of set bits of a given word to the patch, where the word holds bits
+// starting at a given offset. Runs adjacent to the last range are merged into it.
+func (p *Patch) appendRuns(word, offset uint64) {
+	for word != 0 {
+		lo := uint64(bits.TrailingZeros64(word))
+		hi := lo + uint64(bits.TrailingZeros64(^(word >> lo)))
+		if last := len(p.bounds) - 1; last > 0 && p.bounds[last] == offset+lo {
+			p.bounds[last] = offset + hi
+		} else {
+			p.bounds = append(p.bounds, offset+lo, offset+hi)
+		}
+		if hi == wordSize {
+			return
+		}
+		word &= allBitsSet << hi
+	}
+}
+
+// flipWordRange flips all bits in the half-open range [lo, hi) of a word array.
+func flipWordRange(data []uint64, lo, hi uint64) {
+	if lo >= hi {
+		return
+	}
+	first, last := int(lo>>wordSizeLog2), int((hi-1)>>wordSizeLog2)
+	for i := first; i <= last; i++ {
+		mask := allBitsSet
+		if i == first {
+			mask &= allBitsSet << (lo % wordSize)
+		}
+		if i == last {
+			mask &= lastWordMask(hi)
+		}
+		data[i] ^= mask
+	}
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], x)
+	return append(buf, tmp[:n]...)
+}
@@ -0,0 +1,199 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// naiveRanges returns ranges of bits that differ between two bitfields, bit by bit.
+func naiveRanges(a, b Bitfield) [][2]uint64 {
+	ranges := make([][2]uint64, 0)
+	for i := uint64(0); i < a.Len(); i++ {
+		if a.BitAt(i) == b.BitAt(i) {
+			continue
+		}
+		if last := len(ranges) - 1; last >= 0 && ranges[last][1] == i {
+			ranges[last][1]++
+		} else {
+			ranges = append(ranges, [2]uint64{i, i + 1})
+		}
+	}
+	return ranges
+}
+
+func TestDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 63, 64, 65, 200, 1000} {
+		for _, density := range []int{0, 2, 50, 98} {
+			t.Run(fmt.Sprintf("size:%d,density:%d", n, density), func(t *testing.T) {
+				from := NewBitlist64(n)
+				randomBits(r, from, 50)
+				to := from.Clone()
+				// Flip runs of bits, to exercise ranges crossing word boundaries.
+				for i := uint64(0); i < n; i++ {
+					if r.Intn(100) < density {
+						run := uint64(r.Intn(100))
+						for j := i; j < i+run && j < n; j++ {
+							to.SetBitAt(j, !to.BitAt(j))
+						}
+						i += run
+					}
+				}
+
+				p, err := Diff(from, to)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := naiveRanges(from, to)
+				if !reflect.DeepEqual(p.Ranges(), want) {
+					t.Fatalf("Ranges() = %v, wanted %v", p.Ranges(), want)
+				}
+				if p.Len() != n {
+					t.Errorf("Len() = %d, wanted %d", p.Len(), n)
+				}
+				if dist, _ := from.HammingDistance(to); p.Count() != dist {
+					t.Errorf("Count() = %d, wanted %d", p.Count(), dist)
+				}
+
+				// Diffs of []byte backed bitlists, and across types, must be the same.
+				other, err := Diff(from.ToBitlist(), to)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(other.Ranges(), want) {
+					t.Errorf("Ranges() of a Bitlist diff = %v, wanted %v", other.Ranges(), want)
+				}
+
+				b64 := from.Clone()
+				if err := b64.Apply(p); err != nil {
+					t.Fatal(err)
+				}
+				if !b64.Equal(to) {
+					t.Errorf("Apply() = %v, wanted %v", b64, to)
+				}
+				b := from.ToBitlist()
+				if err := b.Apply(p); err != nil {
+					t.Fatal(err)
+				}
+				if !b.Equal(to.ToBitlist()) {
+					t.Errorf("Apply() = %#x, wanted %#x", b, to.ToBitlist())
+				}
+				if err := b.Apply(p.Inverse()); err != nil {
+					t.Fatal(err)
+				}
+				if !b.Equal(from.ToBitlist()) {
+					t.Errorf("Apply() of the inverse = %#x, wanted %#x", b, from.ToBitlist())
+				}
+
+				enc, err := p.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				decoded := &Patch{}
+				if err := decoded.UnmarshalBinary(enc); err != nil {
+					t.Fatal(err)
+				}
+				if decoded.Len() != n || !reflect.DeepEqual(decoded.Ranges(), want) {
+					t.Errorf("UnmarshalBinary() = %d, %v, wanted %d, %v", decoded.Len(), decoded.Ranges(), n, want)
+				}
+			})
+		}
+	}
+}
+
+func TestPatch_Compose(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 64, 100, 1000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			a, b, c := NewBitlist64(n), NewBitlist64(n), NewBitlist64(n)
+			randomBits(r, a, 50)
+			randomBits(r, b, 50)
+			randomBits(r, c, 50)
+
+			ab, err := Diff(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bc, err := Diff(b, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ac, err := ab.Compose(bc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := Diff(a, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ac.Ranges(), want.Ranges()) {
+				t.Errorf("Compose() = %v, wanted %v", ac.Ranges(), want.Ranges())
+			}
+
+			// Composing a patch with its inverse gives an empty patch.
+			empty, err := ab.Compose(ab.Inverse())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if empty.Count() != 0 || len(empty.Ranges()) != 0 {
+				t.Errorf("Compose() with the inverse = %v, wanted no ranges", empty.Ranges())
+			}
+		})
+	}
+
+	t.Run("shared bounds", func(t *testing.T) {
+		p := &Patch{size: 20, bounds: []uint64{0, 5, 10, 15}}
+		q := &Patch{size: 20, bounds: []uint64{5, 10, 15, 20}}
+		pq, err := p.Compose(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := [][2]uint64{{0, 20}}; !reflect.DeepEqual(pq.Ranges(), want) {
+			t.Errorf("Compose() = %v, wanted %v", pq.Ranges(), want)
+		}
+	})
+}
+
+func TestPatch_Errors(t *testing.T) {
+	if _, err := Diff(NewBitlist64(10), NewBitlist(11)); err != ErrBitlistDifferentLength {
+		t.Errorf("Diff() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+	}
+
+	p, err := Diff(NewBitlist(10), NewBitlist(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewBitlist(11).Apply(p); err != ErrBitlistDifferentLength {
+		t.Errorf("Apply() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+	}
+	if err := NewBitlist64(9).Apply(p); err != ErrBitlistDifferentLength {
+		t.Errorf("Apply() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+	}
+	if _, err := p.Compose(&Patch{size: 11}); err != ErrBitlistDifferentLength {
+		t.Errorf("Compose() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "missing number of ranges", data: []byte{10}},
+		{name: "truncated bounds", data: []byte{10, 1, 2}},
+		{name: "too many ranges", data: []byte{10, 100, 1, 1}},
+		{name: "empty range", data: []byte{10, 1, 2, 0}},
+		{name: "touching ranges", data: []byte{10, 2, 1, 1, 0, 1}},
+		{name: "exceeds length", data: []byte{10, 1, 5, 6}},
+		{name: "trailing data", data: []byte{10, 1, 2, 3, 0}},
+		{name: "malformed uvarint", data: []byte{0xFF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (&Patch{}).UnmarshalBinary(tt.data); err != ErrInvalidPatch {
+				t.Errorf("UnmarshalBinary() error = %v, wanted %v", err, ErrInvalidPatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package bitfield
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+var _ = Bitfield(&PersistentBitlist{})
+
+const (
+	// persistentChunkWords is the number of words in a single chunk of a persistent bitlist. A chunk
+	// spans 16 SSZ chunks, so that it forms a complete subtree of the bitlist merkle tree.
+	persistentChunkWords = 64
+	// persistentChunkBits is the number of bits in a single chunk of a persistent bitlist.
+	persistentChunkBits = persistentChunkWords * wordSize
+	// persistentChunkDepth is the depth of the merkle subtree formed by a single chunk.
+	persistentChunkDepth = 4
+)
+
+// persistentChunk is a fixed size piece of a persistent bitlist, shared by all the copies which
+// have not modified it yet.
+type persistentChunk struct {
+	refs int32
+	data [persistentChunkWords]uint64
+	// root caches the merkle root of the chunk as *[32]byte, it is nil until computed.
+	root atomic.Value
+}
+
+// persistentTable is the list of chunks of a persistent bitlist, shared by all the copies which
+// have not been modified yet.
+type persistentTable struct {
+	refs   int32
+	chunks []*persistentChunk
+}
+
+// PersistentBitlist is a copy-on-write bitlist, split into fixed size chunks which are shared
+// between copies. Copy() is O(1), and modifying a copy clones only the chunk being modified, so it
+// fits large bitlists which are copied often, but modified sparingly e.g. participation bits of
+// beacon states. Merkle roots of chunks are cached, so HashTreeRoot() only rehashes chunks that
+// were modified since the last call.
+//
+// Copies can be used from different goroutines, but a single copy is not safe for concurrent use.
+type PersistentBitlist struct {
+	size  uint64
+	table *persistentTable
+}
+
+// NewPersistentBitlist creates a new persistent bitlist of size `n`.
+func NewPersistentBitlist(n uint64) *PersistentBitlist {
+	numChunks := int((n + persistentChunkBits - 1) / persistentChunkBits)
+	chunks := make([]*persistentChunk, numChunks)
+	if numChunks > 0 {
+		// All chunks are empty at this point, so they can share a single one.
+		zero := &persistentChunk{refs: int32(numChunks)}
+		for i := range chunks {
+			chunks[i] = zero
+		}
+	}
+	return &PersistentBitlist{
+		size:  n,
+		table: &persistentTable{refs: 1, chunks: chunks},
+	}
+}
+
+// NewPersistentBitlistFrom creates a new persistent bitlist, copying bits of a given bitlist.
+func NewPersistentBitlistFrom(b *Bitlist64) *PersistentBitlist {
+	ret := NewPersistentBitlist(b.size)
+	for i := range ret.table.chunks {
+		chunk := &persistentChunk{refs: 1}
+		copy(chunk.data[:], b.data[i*persistentChunkWords:])
+		ret.table.chunks[i] = chunk
+	}
+	if len(ret.table.chunks) > 0 {
+		// Make sure no bits are set above the size.
+		last := ret.table.chunks[len(ret.table.chunks)-1]
+		numWords := numWordsRequired(b.size) - (len(ret.table.chunks)-1)*persistentChunkWords
+		last.data[numWords-1] &= lastWordMask(b.size)
+		for i := numWords; i < persistentChunkWords; i++ {
+			last.data[i] = 0
+		}
+	}
+	return ret
+}
+
+// NewPersistentBitlistFromBitlist creates a new persistent bitlist, copying bits of a given
+// []byte backed bitlist.
+func NewPersistentBitlistFromBitlist(b Bitlist) (*PersistentBitlist, error) {
+	b64, err := b.ToBitlist64()
+	if err != nil {
+		return nil, err
+	}
+	return NewPersistentBitlistFrom(b64), nil
+}
+
+// Copy returns a copy of the bitlist in O(1). The copy shares all chunks with the original, until
+// either of them is modified.
+func (b *PersistentBitlist) Copy() *PersistentBitlist {
+	atomic.AddInt32(&b.table.refs, 1)
+	return &PersistentBitlist{
+		size:  b.size,
+		table: b.table,
+	}
+}
+
+// Release drops references to the chunks held by the bitlist, so that other copies can modify them
+// in place instead of cloning. Calling it is optional, as the memory is reclaimed by the garbage
+// collector either way. The bitlist must not be used after it is released.
+func (b *PersistentBitlist) Release() {
+	if b.table == nil {
+		return
+	}
+	if atomic.AddInt32(&b.table.refs, -1) == 0 {
+		for _, chunk := range b.table.chunks {
+			atomic.AddInt32(&chunk.refs, -1)
+		}
+	}
+	b.table = nil
+}
+
+// BitAt returns the bit value at the given index. If the index requested
+// exceeds the number of bits in the bitlist, then this method returns false.
+func (b *PersistentBitlist) BitAt(idx uint64) bool {
+	// Out of bounds, must be false.
+	if idx >= b.size {
+		return false
+	}
+
+	chunk := b.table.chunks[idx/persistentChunkBits]
+	i := uint64(1 << (idx % wordSize))
+	return chunk.data[(idx%persistentChunkBits)>>wordSizeLog2]&i == i
+}
+
+// SetBitAt will set the bit at the given index to the given value, cloning the chunk holding the
+// bit if it is shared with other copies. If the index requested exceeds the number of bits in the
+// bitlist, then this method does nothing.
+func (b *PersistentBitlist) SetBitAt(idx uint64, val bool) {
+	// Out of bounds, or nothing to change, do nothing.
+	if idx >= b.size || b.BitAt(idx) == val {
+		return
+	}
+
+	chunk := b.mutableChunk(int(idx / persistentChunkBits))
+	bit := uint64(1 << (idx % wordSize))
+	if val {
+		chunk.data[(idx%persistentChunkBits)>>wordSizeLog2] |= bit
+	} else {
+		chunk.data[(idx%persistentChunkBits)>>wordSizeLog2] &^= bit
+	}
+}
+
+// Len returns the number of bits in the bitlist.
+func (b *PersistentBitlist) Len() uint64 {
+	return b.size
+}
+
+// Count returns the number of 1s in the bitlist.
+func (b *PersistentBitlist) Count() uint64 {
+	var c int
+	for _, chunk := range b.table.chunks {
+		for _, word := range chunk.data {
+			c += bits.OnesCount64(word)
+		}
+	}
+	return uint64(c)
+}
+
+// Bytes returns bits of the bitlist as an array of bytes.
+// The leading zeros in the bitlist will be trimmed to the smallest byte length representation of
+// the bitlist. This may produce an empty byte slice if all bits were zero.
+func (b *PersistentBitlist) Bytes() []byte {
+	return b.ToBitlist64().Bytes()
+}
+
+// BitIndices returns list of bit indexes of bitlist where value is set to true.
+func (b *PersistentBitlist) BitIndices() []int {
+	indices := make([]int, 0, b.Count())
+	for i, chunk := range b.table.chunks {
+		for j, word := range chunk.data {
+			for word != 0 {
+				indices = append(indices, (i*persistentChunkWords+j)<<wordSizeLog2+bits.TrailingZeros64(word))
+				word &= word - 1
+			}
+		}
+	}
+	return indices
+}
+
+// ToBitlist64 converts the persistent bitlist into a []uint64 backed bitlist.
+func (b *PersistentBitlist) ToBitlist64() *Bitlist64 {
+	ret := NewBitlist64(b.size)
+	for i, chunk := range b.table.chunks {
+		copy(ret.data[i*persistentChunkWords:], chunk.data[:])
+	}
+	return ret
+}
+
+// ToBitlist converts the persistent bitlist into a []byte backed bitlist.
+func (b *PersistentBitlist) ToBitlist() Bitlist {
+	return b.ToBitlist64().ToBitlist()
+}
+
+// HashTreeRoot returns the SSZ hash_tree_root of the bitlist, as a Bitlist with a given limit of
+// bits. Roots of chunks are cached and shared between copies, so only chunks modified since the
+// last call are rehashed.
+// This method will return an error if the bitlist is longer than the limit.
+func (b *PersistentBitlist) HashTreeRoot(limit uint64) ([32]byte, error) {
+	if b.size > limit {
+		return [32]byte{}, ErrExceedsLimit
+	}
+	depth := bitlistDepth(limit)
+	if depth < persistentChunkDepth {
+		// The whole tree is smaller than a single chunk, so there's nothing to cache.
+		var data []uint64
+		if len(b.table.chunks) > 0 {
+			data = b.table.chunks[0].data[:]
+		}
+		return bitlistRoot(data, b.size, limit)
+	}
+
+	layer := make([][32]byte, len(b.table.chunks))
+	for i, chunk := range b.table.chunks {
+		layer[i] = chunk.hashTreeRoot()
+	}
+	return mixInLength(merkleizeLayer(layer, persistentChunkDepth, depth), b.size), nil
+}
+
+// mutableChunk returns the chunk at the given index, making sure that it is not shared with other
+// copies, so that it can be modified in place.
+func (b *PersistentBitlist) mutableChunk(i int) *persistentChunk {
+	if atomic.LoadInt32(&b.table.refs) > 1 {
+		chunks := make([]*persistentChunk, len(b.table.chunks))
+		copy(chunks, b.table.chunks)
+		for _, chunk := range chunks {
+			atomic.AddInt32(&chunk.refs, 1)
+		}
+		b.Release()
+		b.table = &persistentTable{refs: 1, chunks: chunks}
+	}
+
+	chunk := b.table.chunks[i]
+	if atomic.LoadInt32(&chunk.refs) > 1 {
+		clone := &persistentChunk{refs: 1}
+		clone.data = chunk.data
+		atomic.AddInt32(&chunk.refs, -1)
+		b.table.chunks[i] = clone
+		return clone
+	}
+	chunk.root.Store((*[32]byte)(nil))
+	return chunk
+}
+
+// hashTreeRoot returns the root of the merkle subtree formed by the chunk, computing it only if
+// the chunk was modified since the last call.
+func (c *persistentChunk) hashTreeRoot() [32]byte {
+	if root, ok := c.root.Load().(*[32]byte); ok && root != nil {
+		return *root
+	}
+	root := merkleizeWords(c.data[:], persistentChunkDepth)
+	c.root.Store(&root)
+	return root
+}
@@ -0,0 +1,197 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPersistentBitlist_Bitfield(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 63, 64, 4095, 4096, 4097, 10000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			b := NewPersistentBitlist(n)
+			want := NewBitlist64(n)
+			for i := 0; i < 1000; i++ {
+				idx := uint64(r.Int63n(int64(n) + 2))
+				val := r.Intn(3) > 0
+				b.SetBitAt(idx, val)
+				want.SetBitAt(idx, val)
+			}
+
+			if b.Len() != n {
+				t.Errorf("Len() = %d, wanted %d", b.Len(), n)
+			}
+			if b.Count() != want.Count() {
+				t.Errorf("Count() = %d, wanted %d", b.Count(), want.Count())
+			}
+			for i := uint64(0); i < n+2; i++ {
+				if b.BitAt(i) != want.BitAt(i) {
+					t.Fatalf("BitAt(%d) = %t, wanted %t", i, b.BitAt(i), want.BitAt(i))
+				}
+			}
+			if !reflect.DeepEqual(b.BitIndices(), want.BitIndices()) {
+				t.Errorf("BitIndices() = %v, wanted %v", b.BitIndices(), want.BitIndices())
+			}
+			if !reflect.DeepEqual(b.Bytes(), want.Bytes()) {
+				t.Errorf("Bytes() = %#x, wanted %#x", b.Bytes(), want.Bytes())
+			}
+			if !b.ToBitlist64().Equal(want) {
+				t.Errorf("ToBitlist64() = %v, wanted %v", b.ToBitlist64(), want)
+			}
+			if !b.ToBitlist().Equal(want.ToBitlist()) {
+				t.Errorf("ToBitlist() = %#x, wanted %#x", b.ToBitlist(), want.ToBitlist())
+			}
+		})
+	}
+}
+
+func TestNewPersistentBitlistFrom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 64, 4096, 4097, 10000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			want := NewBitlist64(n)
+			randomBits(r, want, 50)
+
+			b := NewPersistentBitlistFrom(want)
+			if !b.ToBitlist64().Equal(want) {
+				t.Errorf("NewPersistentBitlistFrom() = %v, wanted %v", b.ToBitlist64(), want)
+			}
+
+			b, err := NewPersistentBitlistFromBitlist(want.ToBitlist())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !b.ToBitlist64().Equal(want) {
+				t.Errorf("NewPersistentBitlistFromBitlist() = %v, wanted %v", b.ToBitlist64(), want)
+			}
+		})
+	}
+
+	t.Run("bits above size", func(t *testing.T) {
+		b := NewPersistentBitlistFrom(&Bitlist64{size: 65, data: []uint64{0x01, 0xFF}})
+		if b.Count() != 2 || !reflect.DeepEqual(b.BitIndices(), []int{0, 64}) {
+			t.Errorf("BitIndices() = %v, wanted %v", b.BitIndices(), []int{0, 64})
+		}
+	})
+}
+
+func TestPersistentBitlist_Copy(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	n := uint64(5 * persistentChunkBits)
+	original := NewPersistentBitlist(n)
+	randomBits(r, original, 50)
+	want := original.ToBitlist64()
+
+	cp := original.Copy()
+	if cp.table != original.table {
+		t.Fatal("Copy() did not share the chunk table")
+	}
+
+	// Modifying the copy must clone only the touched chunk.
+	idx := uint64(2*persistentChunkBits + 7)
+	cp.SetBitAt(idx, !cp.BitAt(idx))
+	if cp.table == original.table {
+		t.Fatal("SetBitAt() did not clone the chunk table")
+	}
+	for i := range cp.table.chunks {
+		shared := cp.table.chunks[i] == original.table.chunks[i]
+		if shared != (i != 2) {
+			t.Errorf("chunk %d shared = %t, wanted %t", i, shared, i != 2)
+		}
+	}
+	if !original.ToBitlist64().Equal(want) {
+		t.Error("modifying the copy modified the original")
+	}
+	if cp.BitAt(idx) == original.BitAt(idx) {
+		t.Error("copy was not modified")
+	}
+
+	// Modifying the original afterwards must not leak into the copy either.
+	original.SetBitAt(idx+1, !original.BitAt(idx+1))
+	original.SetBitAt(0, !original.BitAt(0))
+	if cp.BitAt(idx+1) == original.BitAt(idx+1) || cp.BitAt(0) == original.BitAt(0) {
+		t.Error("modifying the original modified the copy")
+	}
+
+	// Setting a bit to its current value must not clone anything.
+	cp2 := cp.Copy()
+	cp2.SetBitAt(idx, cp.BitAt(idx))
+	if cp2.table != cp.table {
+		t.Error("SetBitAt() with an unchanged value cloned the chunk table")
+	}
+}
+
+func TestPersistentBitlist_Release(t *testing.T) {
+	b := NewPersistentBitlist(2 * persistentChunkBits)
+	b.SetBitAt(0, true)
+	b.SetBitAt(persistentChunkBits, true)
+
+	cp := b.Copy()
+	cp.Release()
+	chunk := b.table.chunks[0]
+	table := b.table
+	b.SetBitAt(1, true)
+	if b.table != table || b.table.chunks[0] != chunk {
+		t.Error("SetBitAt() cloned after all copies were released")
+	}
+
+	// Releasing twice is harmless.
+	cp.Release()
+	if !reflect.DeepEqual(b.BitIndices(), []int{0, 1, int(persistentChunkBits)}) {
+		t.Errorf("BitIndices() = %v, wanted %v", b.BitIndices(), []int{0, 1, int(persistentChunkBits)})
+	}
+}
+
+func TestPersistentBitlist_HashTreeRoot(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	tests := []struct {
+		size  uint64
+		limit uint64
+	}{
+		{size: 0, limit: 0},
+		{size: 0, limit: 1 << 20},
+		{size: 100, limit: 1000},
+		{size: 4000, limit: 4096},
+		{size: 4096, limit: 4096},
+		{size: 5000, limit: 8192},
+		{size: 20000, limit: 1 << 20},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("size:%d,limit:%d", tt.size, tt.limit), func(t *testing.T) {
+			b := NewPersistentBitlist(tt.size)
+			randomBits(r, b, 50)
+			check := func(b *PersistentBitlist) {
+				got, err := b.HashTreeRoot(tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := specBitlistRoot(b, tt.limit); got != want {
+					t.Fatalf("HashTreeRoot() = %#x, wanted %#x", got, want)
+				}
+			}
+			check(b)
+
+			// Cached roots must be invalidated by modifications, and not leak between copies.
+			cp := b.Copy()
+			for i := 0; i < 10 && tt.size > 0; i++ {
+				idx := uint64(r.Int63n(int64(tt.size)))
+				cp.SetBitAt(idx, !cp.BitAt(idx))
+				check(cp)
+				check(b)
+			}
+			b.Release()
+			for i := 0; i < 10 && tt.size > 0; i++ {
+				cp.SetBitAt(uint64(r.Int63n(int64(tt.size))), true)
+				check(cp)
+			}
+		})
+	}
+
+	t.Run("exceeds limit", func(t *testing.T) {
+		if _, err := NewPersistentBitlist(10).HashTreeRoot(9); err != ErrExceedsLimit {
+			t.Errorf("HashTreeRoot() error = %v, wanted %v", err, ErrExceedsLimit)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package bitfield
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// Bitlist64Pool is a pool of bitlists, bucketed by the power of two of their number of words, which
+// allows reusing results of operations instead of allocating them e.g. in loops building many
+// short-lived bitlists. A bitlist taken from a bucket is resliced to the requested size, so sizes
+// sharing a bucket reuse each other's arrays, at the cost of pooled arrays being up to twice as
+// long as needed. It is backed by one sync.Pool per bucket, so unused bitlists are eventually
+// released to the GC, and the number of buckets is bounded regardless of the sizes requested.
+//
+// The zero value is ready to use, and a nil pool allocates every bitlist, so that pooling can be
+// made optional. A pool is safe for concurrent use.
+type Bitlist64Pool struct {
+	pools [bits.UintSize]sync.Pool // Bitlists with at least 1<<i words of capacity at index i.
+}
+
+// Get returns a bitlist of size `n` with no bits set, either reused from the pool or newly
+// allocated.
+func (p *Bitlist64Pool) Get(n uint64) *Bitlist64 {
+	b := p.get(n)
+	for idx := range b.data {
+		b.data[idx] = 0
+	}
+	return b
+}
+
+// Put returns a bitlist to the pool, so that it can be reused by Get or *Pooled operations. The
+// bitlist must not be used after it is returned. Bitlists not allocated by NewBitlist64 or the pool,
+// e.g. backed by an array of the wrong length, are ignored.
+func (p *Bitlist64Pool) Put(b *Bitlist64) {
+	if p == nil || b == nil || len(b.data) != numWordsRequired(b.size) || cap(b.data) == 0 {
+		return
+	}
+	// File the bitlist under the largest bucket its capacity satisfies.
+	p.pools[bits.Len(uint(cap(b.data)))-1].Put(b)
+}
+
+// get returns a bitlist of size `n`, which may hold bits of a previous use.
+func (p *Bitlist64Pool) get(n uint64) *Bitlist64 {
+	if p == nil {
+		return NewBitlist64(n)
+	}
+	numWords := numWordsRequired(n)
+	bucket := poolBucket(numWords)
+	if b, ok := p.pools[bucket].Get().(*Bitlist64); ok {
+		b.size = n
+		b.data = b.data[:numWords]
+		return b
+	}
+	return &Bitlist64{
+		size: n,
+		data: make([]uint64, numWords, 1<<bucket),
+	}
+}
+
+// poolBucket returns the smallest bucket whose bitlists can hold `numWords` words.
+func poolBucket(numWords int) int {
+	if numWords <= 1 {
+		return 0
+	}
+	return bits.Len(uint(numWords - 1))
+}
@@ -0,0 +1,158 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBitlist64Pool(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, pool := range []*Bitlist64Pool{nil, {}} {
+		for _, n := range []uint64{0, 1, 64, 100, 1000} {
+			t.Run(fmt.Sprintf("nil:%t,size:%d", pool == nil, n), func(t *testing.T) {
+				for i := 0; i < 10; i++ {
+					b := pool.Get(n)
+					if !reflect.DeepEqual(b, NewBitlist64(n)) {
+						t.Fatalf("Get() = %+v, wanted %+v", b, NewBitlist64(n))
+					}
+					// Dirty bitlists must be zeroed on reuse.
+					randomBits(r, b, 50)
+					pool.Put(b)
+				}
+			})
+		}
+	}
+
+	t.Run("sizes are not mixed", func(t *testing.T) {
+		pool := &Bitlist64Pool{}
+		for i := 0; i < 10; i++ {
+			pool.Put(NewBitlist64(100))
+			if b := pool.Get(64); b.Len() != 64 || len(b.data) != 1 {
+				t.Errorf("Get(64) = %+v, wanted a bitlist of 64 bits", b)
+			}
+		}
+	})
+
+	t.Run("malformed bitlists are ignored", func(t *testing.T) {
+		pool := &Bitlist64Pool{}
+		for i := 0; i < 10; i++ {
+			pool.Put(&Bitlist64{size: 100, data: []uint64{0x01}})
+			pool.Put(nil)
+			if b := pool.Get(100); len(b.data) != 2 {
+				t.Errorf("Get(100) = %+v, wanted 2 words", b)
+			}
+		}
+	})
+
+	t.Run("sizes sharing a bucket", func(t *testing.T) {
+		pool := &Bitlist64Pool{}
+		for n := uint64(1); n <= 4096; n++ {
+			b := pool.Get(n)
+			if b.Len() != n || len(b.data) != numWordsRequired(n) || b.Count() != 0 {
+				t.Fatalf("Get(%d) = %+v, wanted an empty bitlist of %d bits", n, b, n)
+			}
+			randomBits(r, b, 50)
+			pool.Put(b)
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		pool := &Bitlist64Pool{}
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					n := uint64(64 * (1 + (g+i)%4))
+					b := pool.Get(n)
+					if b.Count() != 0 || b.Len() != n {
+						t.Errorf("Get(%d) = %+v, wanted an empty bitlist", n, b)
+					}
+					b.SetBitAt(uint64(i)%n, true)
+					pool.Put(b)
+				}
+			}(g)
+		}
+		wg.Wait()
+	})
+}
+
+func TestBitlist64_Pooled(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, pool := range []*Bitlist64Pool{nil, {}} {
+		for _, n := range []uint64{0, 1, 63, 64, 65, 1000} {
+			t.Run(fmt.Sprintf("nil:%t,size:%d", pool == nil, n), func(t *testing.T) {
+				for i := 0; i < 5; i++ {
+					b, c := NewBitlist64(n), NewBitlist64(n)
+					randomBits(r, b, 50)
+					randomBits(r, c, 50)
+
+					ops := []struct {
+						name   string
+						pooled func() (*Bitlist64, error)
+						want   func() (*Bitlist64, error)
+					}{
+						{name: "Or", pooled: func() (*Bitlist64, error) { return b.OrPooled(c, pool) }, want: func() (*Bitlist64, error) { return b.Or(c) }},
+						{name: "And", pooled: func() (*Bitlist64, error) { return b.AndPooled(c, pool) }, want: func() (*Bitlist64, error) { return b.And(c) }},
+						{name: "Xor", pooled: func() (*Bitlist64, error) { return b.XorPooled(c, pool) }, want: func() (*Bitlist64, error) { return b.Xor(c) }},
+						{name: "Not", pooled: func() (*Bitlist64, error) { return b.NotPooled(pool), nil }, want: func() (*Bitlist64, error) { return b.Not(), nil }},
+					}
+					for _, op := range ops {
+						got, err := op.pooled()
+						if err != nil {
+							t.Fatal(err)
+						}
+						want, err := op.want()
+						if err != nil {
+							t.Fatal(err)
+						}
+						if !got.Equal(want) || got.Count() != want.Count() {
+							t.Errorf("%sPooled() = %v, wanted %v", op.name, got.BitIndices(), want.BitIndices())
+						}
+						// Results are returned dirty, to be reused by the next iteration.
+						pool.Put(got)
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("different lengths", func(t *testing.T) {
+		pool := &Bitlist64Pool{}
+		b, c := NewBitlist64(10), NewBitlist64(11)
+		if _, err := b.OrPooled(c, pool); err != ErrBitlistDifferentLength {
+			t.Errorf("OrPooled() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := b.AndPooled(c, pool); err != ErrBitlistDifferentLength {
+			t.Errorf("AndPooled() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		if _, err := b.XorPooled(c, pool); err != ErrBitlistDifferentLength {
+			t.Errorf("XorPooled() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+	})
+}
+
+func TestPoolBucket(t *testing.T) {
+	tests := []struct {
+		numWords int
+		want     int
+	}{
+		{numWords: 0, want: 0},
+		{numWords: 1, want: 0},
+		{numWords: 2, want: 1},
+		{numWords: 3, want: 2},
+		{numWords: 4, want: 2},
+		{numWords: 5, want: 3},
+		{numWords: 1 << 20, want: 20},
+		{numWords: 1<<20 + 1, want: 21},
+	}
+	for _, tt := range tests {
+		if got := poolBucket(tt.numWords); got != tt.want {
+			t.Errorf("poolBucket(%d) = %d, wanted %d", tt.numWords, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package bitfield
+
+import (
+	"math/bits"
+)
+
+// BitRing is a sliding window over the most recent bits pushed into it, backed by a ring buffer of
+// a fixed capacity e.g. per-validator liveness over the last N epochs. Unlike Bitvector*.Shift or
+// BitHistory.Record, pushing a bit is O(1), as only the head position moves.
+type BitRing struct {
+	bits   *Bitlist64
+	head   uint64 // Position the next bit is written to.
+	length uint64 // Number of bits recorded, up to capacity.
+}
+
+// NewBitRing creates an empty ring keeping up to `capacity` most recent bits.
+func NewBitRing(capacity uint64) *BitRing {
+	return &BitRing{
+		bits: NewBitlist64(capacity),
+	}
+}
+
+// Cap returns the maximum number of bits kept in the ring.
+func (r *BitRing) Cap() uint64 {
+	return r.bits.Len()
+}
+
+// Len returns the number of bits currently kept in the ring.
+func (r *BitRing) Len() uint64 {
+	return r.length
+}
+
+// Push records a bit, evicting the oldest one if the ring is full.
+func (r *BitRing) Push(bit bool) {
+	capacity := r.bits.Len()
+	if capacity == 0 {
+		return
+	}
+	r.bits.SetBitAt(r.head, bit)
+	r.head++
+	if r.head == capacity {
+		r.head = 0
+	}
+	if r.length < capacity {
+		r.length++
+	}
+}
+
+// At returns the bit pushed `age` pushes ago, where age 0 is the most recent bit. If the age
+// exceeds the number of bits kept, then this method returns false.
+func (r *BitRing) At(age uint64) bool {
+	if age >= r.length {
+		return false
+	}
+	return r.bits.BitAt(r.position(age))
+}
+
+// CountLast returns the number of 1s among the last k bits pushed. If k exceeds the number of bits
+// kept, all of them are counted.
+func (r *BitRing) CountLast(k uint64) uint64 {
+	if k > r.length {
+		k = r.length
+	}
+	if k == 0 {
+		return 0
+	}
+	start := r.position(k - 1)
+	if start < r.head {
+		return countBitRange(r.bits.data, start, r.head)
+	}
+	// The range wraps around the end of the buffer.
+	return countBitRange(r.bits.data, start, r.bits.Len()) + countBitRange(r.bits.data, 0, r.head)
+}
+
+// Snapshot returns the bits kept in the ring in chronological order i.e. bit 0 of the returned
+// bitlist is the oldest bit, and bit Len()-1 is the most recent one.
+func (r *BitRing) Snapshot() *Bitlist64 {
+	ret := NewBitlist64(r.length)
+	if r.length == 0 {
+		return ret
+	}
+	start := r.position(r.length - 1)
+	if start < r.head {
+		copyBitRange(ret.data, 0, r.bits.data, start, r.length)
+		return ret
+	}
+	tail := r.bits.Len() - start
+	copyBitRange(ret.data, 0, r.bits.data, start, tail)
+	copyBitRange(ret.data, tail, r.bits.data, 0, r.head)
+	return ret
+}
+
+// position returns the buffer position of the bit pushed `age` pushes ago.
+func (r *BitRing) position(age uint64) uint64 {
+	capacity := r.bits.Len()
+	return (r.head + capacity - 1 - age) % capacity
+}
+
+// countBitRange counts the bits set in the half-open range [lo, hi) of a word array.
+func countBitRange(data []uint64, lo, hi uint64) uint64 {
+	if lo >= hi {
+		return 0
+	}
+	first, last := lo>>wordSizeLog2, (hi-1)>>wordSizeLog2
+	loMask := allBitsSet << (lo % wordSize)
+	hiMask := lastWordMask(hi)
+	if first == last {
+		return uint64(bits.OnesCount64(data[first] & loMask & hiMask))
+	}
+	cnt := bits.OnesCount64(data[first] & loMask)
+	for i := first + 1; i < last; i++ {
+		cnt += bits.OnesCount64(data[i])
+	}
+	cnt += bits.OnesCount64(data[last] & hiMask)
+	return uint64(cnt)
+}
+
+// copyBitRange copies n bits from src starting at bit srcOff into dst starting at bit dstOff,
+// moving up to a word at a time.
+func copyBitRange(dst []uint64, dstOff uint64, src []uint64, srcOff, n uint64) {
+	for n > 0 {
+		chunk := n
+		if chunk > wordSize {
+			chunk = wordSize
+		}
+		putBits(dst, dstOff, chunk, getBits(src, srcOff, chunk))
+		dstOff += chunk
+		srcOff += chunk
+		n -= chunk
+	}
+}
+
+// getBits returns n <= 64 bits of a word array starting at a given bit offset.
+func getBits(data []uint64, off, n uint64) uint64 {
+	idx, shift := off>>wordSizeLog2, off%wordSize
+	v := data[idx] >> shift
+	if shift != 0 && shift+n > wordSize {
+		v |= data[idx+1] << (wordSize - shift)
+	}
+	return v & lastWordMask(n)
+}
+
+// putBits overwrites n <= 64 bits of a word array starting at a given bit offset.
+func putBits(data []uint64, off, n, v uint64) {
+	idx, shift := off>>wordSizeLog2, off%wordSize
+	mask := lastWordMask(n)
+	v &= mask
+	data[idx] = data[idx]&^(mask<<shift) | v<<shift
+	if shift != 0 && shift+n > wordSize {
+		data[idx+1] = data[idx+1]&^(mask>>(wordSize-shift)) | v>>(wordSize-shift)
+	}
+}
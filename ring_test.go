@@ -0,0 +1,104 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestBitRing_Push(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, capacity := range []uint64{1, 3, 63, 64, 65, 200} {
+		t.Run(fmt.Sprintf("cap:%d", capacity), func(t *testing.T) {
+			ring := NewBitRing(capacity)
+			var records []bool
+			for i := 0; i < 500; i++ {
+				bit := r.Intn(2) == 1
+				records = append(records, bit)
+				ring.Push(bit)
+
+				wantLen := uint64(len(records))
+				if wantLen > capacity {
+					wantLen = capacity
+				}
+				if ring.Len() != wantLen || ring.Cap() != capacity {
+					t.Fatalf("Len() = %d, Cap() = %d, wanted %d, %d", ring.Len(), ring.Cap(), wantLen, capacity)
+				}
+				recent := records[len(records)-int(wantLen):]
+
+				// Check a sample of ages and counts, to keep the test fast.
+				age := uint64(r.Intn(int(capacity) + 2))
+				wantBit := age < wantLen && recent[len(recent)-1-int(age)]
+				if ring.At(age) != wantBit {
+					t.Fatalf("At(%d) = %t, wanted %t", age, ring.At(age), wantBit)
+				}
+				k := uint64(r.Intn(int(capacity) + 2))
+				var wantCount uint64
+				for j := 0; j < len(recent) && uint64(j) < k; j++ {
+					if recent[len(recent)-1-j] {
+						wantCount++
+					}
+				}
+				if got := ring.CountLast(k); got != wantCount {
+					t.Fatalf("CountLast(%d) = %d, wanted %d", k, got, wantCount)
+				}
+
+				snapshot := ring.Snapshot()
+				if snapshot.Len() != wantLen {
+					t.Fatalf("Snapshot().Len() = %d, wanted %d", snapshot.Len(), wantLen)
+				}
+				for j, bit := range recent {
+					if snapshot.BitAt(uint64(j)) != bit {
+						t.Fatalf("Snapshot().BitAt(%d) = %t, wanted %t", j, snapshot.BitAt(uint64(j)), bit)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("zero capacity", func(t *testing.T) {
+		ring := NewBitRing(0)
+		ring.Push(true)
+		if ring.Len() != 0 || ring.At(0) || ring.CountLast(1) != 0 || ring.Snapshot().Len() != 0 {
+			t.Error("Ring with zero capacity recorded a bit")
+		}
+	})
+}
+
+func TestCopyBitRange(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	src := NewBitlist64(300)
+	randomBits(r, src, 50)
+	for i := 0; i < 200; i++ {
+		srcOff := uint64(r.Intn(300))
+		n := uint64(r.Intn(int(300 - srcOff + 1)))
+		dstOff := uint64(r.Intn(int(300 - n + 1)))
+		dst := NewBitlist64(300)
+		randomBits(r, dst, 50)
+		orig := dst.Clone()
+
+		copyBitRange(dst.data, dstOff, src.data, srcOff, n)
+		for j := uint64(0); j < 300; j++ {
+			want := orig.BitAt(j)
+			if j >= dstOff && j < dstOff+n {
+				want = src.BitAt(srcOff + j - dstOff)
+			}
+			if dst.BitAt(j) != want {
+				t.Fatalf("copyBitRange(%d, %d, %d): bit %d = %t, wanted %t", dstOff, srcOff, n, j, dst.BitAt(j), want)
+			}
+		}
+		if got, want := countBitRange(src.data, srcOff, srcOff+n), naiveCount(src, srcOff, srcOff+n); got != want {
+			t.Fatalf("countBitRange(%d, %d) = %d, wanted %d", srcOff, srcOff+n, got, want)
+		}
+	}
+}
+
+func naiveCount(b Bitfield, lo, hi uint64) uint64 {
+	var cnt uint64
+	for i := lo; i < hi; i++ {
+		if b.BitAt(i) {
+			cnt++
+		}
+	}
+	return cnt
+}
@@ -0,0 +1,319 @@
+package bitfield
+
+import (
+	"math/bits"
+	"sort"
+)
+
+var _ = Bitfield(&SparseBitlist{})
+
+// sparseMaxLen is the largest bitlist that can be represented as a list of uint32 indices.
+const sparseMaxLen = 1 << 32
+
+// SparseBitlist is a bitlist which switches between two representations depending on its density.
+// While few bits are set (e.g. slashings), it stores a sorted list of indices of set bits. Once
+// the list would take more memory than words holding all bits, it switches to a *Bitlist64 (e.g.
+// participation), and switches back when the number of set bits drops to half of that. Bitlists
+// longer than 2^32 bits always use words.
+type SparseBitlist struct {
+	size    uint64
+	count   uint64
+	indices []uint32   // Sorted indices of set bits, when sparse.
+	dense   *Bitlist64 // Bits, when dense. Nil when sparse.
+}
+
+// NewSparseBitlist creates a new bitlist of size `n`, with no bits set.
+func NewSparseBitlist(n uint64) *SparseBitlist {
+	if n > sparseMaxLen {
+		return &SparseBitlist{size: n, dense: NewBitlist64(n)}
+	}
+	return &SparseBitlist{size: n, indices: make([]uint32, 0)}
+}
+
+// NewSparseBitlistFrom creates a new bitlist, copying bits of a given bitlist, and choosing the
+// representation according to its density.
+func NewSparseBitlistFrom(b *Bitlist64) *SparseBitlist {
+	dense := b.Clone()
+	dense.clearUnusedBits()
+	return newSparseFromDense(dense)
+}
+
+// newSparseFromDense wraps a given bitlist without copying, switching to indices if it's sparse.
+func newSparseFromDense(dense *Bitlist64) *SparseBitlist {
+	b := &SparseBitlist{size: dense.size, count: dense.Count(), dense: dense}
+	b.normalize()
+	return b
+}
+
+// newSparseFromIndices wraps a given list of indices without copying, switching to words if it's
+// dense.
+func newSparseFromIndices(n uint64, indices []uint32) *SparseBitlist {
+	b := &SparseBitlist{size: n, count: uint64(len(indices)), indices: indices}
+	b.normalize()
+	return b
+}
+
+// IsSparse returns true if the bitlist is currently represented as a list of indices.
+func (b *SparseBitlist) IsSparse() bool {
+	return b.dense == nil
+}
+
+// BitAt returns the bit value at the given index. If the index requested
+// exceeds the number of bits in the bitlist, then this method returns false.
+func (b *SparseBitlist) BitAt(idx uint64) bool {
+	if idx >= b.size {
+		return false
+	}
+	if b.dense != nil {
+		return b.dense.BitAt(idx)
+	}
+	i := b.search(idx)
+	return i < len(b.indices) && uint64(b.indices[i]) == idx
+}
+
+// SetBitAt will set the bit at the given index to the given value, switching the representation
+// if the density crosses the threshold. If the index requested exceeds the number of bits in the
+// bitlist, then this method does nothing.
+func (b *SparseBitlist) SetBitAt(idx uint64, val bool) {
+	// Out of bounds, or nothing to change, do nothing.
+	if idx >= b.size || b.BitAt(idx) == val {
+		return
+	}
+
+	if b.dense != nil {
+		b.dense.SetBitAt(idx, val)
+	} else {
+		i := b.search(idx)
+		if val {
+			b.indices = append(b.indices, 0)
+			copy(b.indices[i+1:], b.indices[i:])
+			b.indices[i] = uint32(idx)
+		} else {
+			b.indices = append(b.indices[:i], b.indices[i+1:]...)
+		}
+	}
+	if val {
+		b.count++
+	} else {
+		b.count--
+	}
+	b.normalize()
+}
+
+// Len returns the number of bits in the bitlist.
+func (b *SparseBitlist) Len() uint64 {
+	return b.size
+}
+
+// Count returns the number of 1s in the bitlist.
+func (b *SparseBitlist) Count() uint64 {
+	return b.count
+}
+
+// Bytes returns bits of the bitlist as an array of bytes.
+// The leading zeros in the bitlist will be trimmed to the smallest byte length representation of
+// the bitlist. This may produce an empty byte slice if all bits were zero.
+func (b *SparseBitlist) Bytes() []byte {
+	return b.ToBitlist64().Bytes()
+}
+
+// BitIndices returns list of bit indexes of bitlist where value is set to true.
+func (b *SparseBitlist) BitIndices() []int {
+	if b.dense != nil {
+		return b.dense.BitIndices()
+	}
+	indices := make([]int, len(b.indices))
+	for i, idx := range b.indices {
+		indices[i] = int(idx)
+	}
+	return indices
+}
+
+// ToBitlist64 returns a copy of the bitlist as a []uint64 backed bitlist.
+func (b *SparseBitlist) ToBitlist64() *Bitlist64 {
+	if b.dense != nil {
+		return b.dense.Clone()
+	}
+	ret := NewBitlist64(b.size)
+	for _, idx := range b.indices {
+		ret.data[idx>>wordSizeLog2] |= 1 << (uint64(idx) % wordSize)
+	}
+	return ret
+}
+
+// Equal returns true if both bitlists have the same length and the same bits set, regardless of
+// their representations.
+func (b *SparseBitlist) Equal(c *SparseBitlist) bool {
+	return Equal(b, c)
+}
+
+// Or returns the OR result of the two bitlists, in the representation suiting its density.
+// This method will return an error if the bitlists are not the same length.
+func (b *SparseBitlist) Or(c *SparseBitlist) (*SparseBitlist, error) {
+	if b.size != c.size {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	switch {
+	case b.dense == nil && c.dense == nil:
+		return newSparseFromIndices(b.size, mergeIndices(b.indices, c.indices, func(x, y bool) bool {
+			return x || y
+		})), nil
+	case b.dense != nil && c.dense != nil:
+		ret, err := b.dense.Or(c.dense)
+		if err != nil {
+			return nil, err
+		}
+		return newSparseFromDense(ret), nil
+	default:
+		sparse, dense := b.sortByRepresentation(c)
+		ret := dense.dense.Clone()
+		for _, idx := range sparse.indices {
+			ret.data[idx>>wordSizeLog2] |= 1 << (uint64(idx) % wordSize)
+		}
+		return newSparseFromDense(ret), nil
+	}
+}
+
+// And returns the AND result of the two bitlists, in the representation suiting its density.
+// This method will return an error if the bitlists are not the same length.
+func (b *SparseBitlist) And(c *SparseBitlist) (*SparseBitlist, error) {
+	if b.size != c.size {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	switch {
+	case b.dense == nil && c.dense == nil:
+		return newSparseFromIndices(b.size, mergeIndices(b.indices, c.indices, func(x, y bool) bool {
+			return x && y
+		})), nil
+	case b.dense != nil && c.dense != nil:
+		ret, err := b.dense.And(c.dense)
+		if err != nil {
+			return nil, err
+		}
+		return newSparseFromDense(ret), nil
+	default:
+		sparse, dense := b.sortByRepresentation(c)
+		indices := make([]uint32, 0, len(sparse.indices))
+		for _, idx := range sparse.indices {
+			if dense.dense.BitAt(uint64(idx)) {
+				indices = append(indices, idx)
+			}
+		}
+		return newSparseFromIndices(b.size, indices), nil
+	}
+}
+
+// Xor returns the XOR result of the two bitlists, in the representation suiting its density.
+// This method will return an error if the bitlists are not the same length.
+func (b *SparseBitlist) Xor(c *SparseBitlist) (*SparseBitlist, error) {
+	if b.size != c.size {
+		return nil, ErrBitlistDifferentLength
+	}
+
+	switch {
+	case b.dense == nil && c.dense == nil:
+		return newSparseFromIndices(b.size, mergeIndices(b.indices, c.indices, func(x, y bool) bool {
+			return x != y
+		})), nil
+	case b.dense != nil && c.dense != nil:
+		ret, err := b.dense.Xor(c.dense)
+		if err != nil {
+			return nil, err
+		}
+		return newSparseFromDense(ret), nil
+	default:
+		sparse, dense := b.sortByRepresentation(c)
+		ret := dense.dense.Clone()
+		for _, idx := range sparse.indices {
+			ret.data[idx>>wordSizeLog2] ^= 1 << (uint64(idx) % wordSize)
+		}
+		return newSparseFromDense(ret), nil
+	}
+}
+
+// MarshalBinary encodes the bitlist as a container (see Encode), choosing whichever payload
+// layout is the smallest: raw words, runs or an index list.
+func (b *SparseBitlist) MarshalBinary() ([]byte, error) {
+	return Encode(b)
+}
+
+// UnmarshalBinary decodes a container holding any bitlist or bitvector, replacing the bitlist.
+// This method will return an error if the container is malformed or corrupted.
+func (b *SparseBitlist) UnmarshalBinary(data []byte) error {
+	decoded, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	if sparse, ok := decoded.(*SparseBitlist); ok {
+		*b = *sparse
+		return nil
+	}
+	words, n := bitWords(decoded)
+	ret := &Bitlist64{size: n, data: append([]uint64(nil), words[:numWordsRequired(n)]...)}
+	ret.clearUnusedBits()
+	*b = *newSparseFromDense(ret)
+	return nil
+}
+
+// normalize switches the representation, if the number of set bits crossed the threshold.
+func (b *SparseBitlist) normalize() {
+	numWords := uint64(numWordsRequired(b.size))
+	switch {
+	case b.dense == nil && b.count > 2*numWords:
+		// Indices take more memory than words.
+		b.dense = b.ToBitlist64()
+		b.indices = nil
+	case b.dense != nil && b.count <= numWords && b.size <= sparseMaxLen:
+		indices := make([]uint32, 0, b.count)
+		for idx, word := range b.dense.data {
+			for word != 0 {
+				indices = append(indices, uint32(idx<<wordSizeLog2+bits.TrailingZeros64(word)))
+				word &= word - 1
+			}
+		}
+		b.indices = indices
+		b.dense = nil
+	}
+}
+
+// search returns the position of the first index not smaller than idx in the list of indices.
+func (b *SparseBitlist) search(idx uint64) int {
+	return sort.Search(len(b.indices), func(i int) bool {
+		return uint64(b.indices[i]) >= idx
+	})
+}
+
+// sortByRepresentation returns the sparse and the dense bitlist out of b and c, which must have
+// different representations.
+func (b *SparseBitlist) sortByRepresentation(c *SparseBitlist) (*SparseBitlist, *SparseBitlist) {
+	if b.dense == nil {
+		return b, c
+	}
+	return c, b
+}
+
+// mergeIndices merges two sorted lists of indices, keeping indices for which keep returns true,
+// given whether the index is present in each of the lists.
+func mergeIndices(a, b []uint32, keep func(inA, inB bool) bool) []uint32 {
+	ret := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		var idx uint32
+		inA := i < len(a) && (j == len(b) || a[i] <= b[j])
+		inB := j < len(b) && (i == len(a) || b[j] <= a[i])
+		if inA {
+			idx = a[i]
+			i++
+		}
+		if inB {
+			idx = b[j]
+			j++
+		}
+		if keep(inA, inB) {
+			ret = append(ret, idx)
+		}
+	}
+	return ret
+}
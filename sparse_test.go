@@ -0,0 +1,171 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSparseBitlist_Bitfield(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 64, 100, 1000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			b := NewSparseBitlist(n)
+			want := NewBitlist64(n)
+			sawSparse, sawDense := false, false
+			// Grow and shrink the density, so that the representation switches both ways.
+			for _, density := range []int{1, 10, 50, 100, 50, 10, 1, 0} {
+				for i := 0; i < 2*int(n)+10; i++ {
+					idx := uint64(r.Int63n(int64(n) + 2))
+					val := r.Intn(100) < density
+					b.SetBitAt(idx, val)
+					want.SetBitAt(idx, val)
+				}
+				if b.IsSparse() {
+					sawSparse = true
+				} else {
+					sawDense = true
+				}
+
+				if b.Len() != n || b.Count() != want.Count() {
+					t.Fatalf("Len() = %d, Count() = %d, wanted %d, %d", b.Len(), b.Count(), n, want.Count())
+				}
+				for i := uint64(0); i < n+2; i++ {
+					if b.BitAt(i) != want.BitAt(i) {
+						t.Fatalf("BitAt(%d) = %t, wanted %t", i, b.BitAt(i), want.BitAt(i))
+					}
+				}
+				if !reflect.DeepEqual(b.BitIndices(), want.BitIndices()) {
+					t.Fatalf("BitIndices() = %v, wanted %v", b.BitIndices(), want.BitIndices())
+				}
+				if !reflect.DeepEqual(b.Bytes(), want.Bytes()) {
+					t.Fatalf("Bytes() = %#x, wanted %#x", b.Bytes(), want.Bytes())
+				}
+				if !b.ToBitlist64().Equal(want) {
+					t.Fatalf("ToBitlist64() = %v, wanted %v", b.ToBitlist64(), want)
+				}
+
+				numWords := uint64(numWordsRequired(n))
+				if b.IsSparse() && b.Count() > 2*numWords {
+					t.Fatalf("sparse with %d bits set, wanted at most %d", b.Count(), 2*numWords)
+				}
+				if !b.IsSparse() && b.Count() <= numWords {
+					t.Fatalf("dense with %d bits set, wanted more than %d", b.Count(), numWords)
+				}
+			}
+			if n >= 64 && (!sawSparse || !sawDense) {
+				t.Errorf("representations seen: sparse = %t, dense = %t, wanted both", sawSparse, sawDense)
+			}
+		})
+	}
+}
+
+func TestSparseBitlist_SetOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	type op struct {
+		name  string
+		f     func(b, c *SparseBitlist) (*SparseBitlist, error)
+		naive func(x, y bool) bool
+	}
+	ops := []op{
+		{name: "or", f: (*SparseBitlist).Or, naive: func(x, y bool) bool { return x || y }},
+		{name: "and", f: (*SparseBitlist).And, naive: func(x, y bool) bool { return x && y }},
+		{name: "xor", f: (*SparseBitlist).Xor, naive: func(x, y bool) bool { return x != y }},
+	}
+	for _, n := range []uint64{0, 10, 1000} {
+		for _, densities := range [][2]int{{1, 1}, {1, 60}, {60, 1}, {60, 60}, {2, 3}, {100, 100}} {
+			for _, o := range ops {
+				t.Run(fmt.Sprintf("%s,size:%d,density:%v", o.name, n, densities), func(t *testing.T) {
+					a, b := NewBitlist64(n), NewBitlist64(n)
+					randomBits(r, a, densities[0])
+					randomBits(r, b, densities[1])
+					x, y := NewSparseBitlistFrom(a), NewSparseBitlistFrom(b)
+
+					got, err := o.f(x, y)
+					if err != nil {
+						t.Fatal(err)
+					}
+					want := NewBitlist64(n)
+					for i := uint64(0); i < n; i++ {
+						want.SetBitAt(i, o.naive(a.BitAt(i), b.BitAt(i)))
+					}
+					if !got.ToBitlist64().Equal(want) || got.Count() != want.Count() {
+						t.Errorf("%s() = %v, wanted %v", o.name, got.BitIndices(), want.BitIndices())
+					}
+					wantSparse := want.Count() <= 2*uint64(numWordsRequired(n))
+					if !got.IsSparse() && want.Count() <= uint64(numWordsRequired(n)) {
+						t.Errorf("%s() result is dense with %d bits set", o.name, want.Count())
+					}
+					if got.IsSparse() && !wantSparse {
+						t.Errorf("%s() result is sparse with %d bits set", o.name, want.Count())
+					}
+
+					// Operands must not be modified.
+					if !x.ToBitlist64().Equal(a) || !y.ToBitlist64().Equal(b) {
+						t.Errorf("%s() modified its operands", o.name)
+					}
+				})
+			}
+		}
+	}
+
+	t.Run("different lengths", func(t *testing.T) {
+		for _, o := range ops {
+			if _, err := o.f(NewSparseBitlist(10), NewSparseBitlist(11)); err != ErrBitlistDifferentLength {
+				t.Errorf("%s() error = %v, wanted %v", o.name, err, ErrBitlistDifferentLength)
+			}
+		}
+	})
+}
+
+func TestSparseBitlist_MarshalBinary(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, density := range []int{0, 1, 10, 50, 100} {
+		t.Run(fmt.Sprintf("density:%d", density), func(t *testing.T) {
+			want := NewSparseBitlist(10000)
+			randomBits(r, want, density)
+			data, err := want.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, enc := range []PayloadEncoding{EncodingRaw, EncodingRLE, EncodingSparse} {
+				other, err := EncodeWith(want, enc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(other) < len(data) {
+					t.Errorf("MarshalBinary() size = %d, encoding %d is smaller: %d", len(data), enc, len(other))
+				}
+			}
+
+			got := &SparseBitlist{}
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) || got.IsSparse() != want.IsSparse() || got.Count() != want.Count() {
+				t.Errorf("UnmarshalBinary() = %v, wanted %v", got.BitIndices(), want.BitIndices())
+			}
+		})
+	}
+
+	t.Run("other bitfield types", func(t *testing.T) {
+		b := NewBitlist(100)
+		b.SetBitAt(7, true)
+		data, err := Encode(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := &SparseBitlist{}
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if got.Len() != 100 || !reflect.DeepEqual(got.BitIndices(), []int{7}) || !got.IsSparse() {
+			t.Errorf("UnmarshalBinary() = %v, wanted %v", got.BitIndices(), []int{7})
+		}
+
+		if err := got.UnmarshalBinary(data[1:]); err != ErrInvalidMagic {
+			t.Errorf("UnmarshalBinary() error = %v, wanted %v", err, ErrInvalidMagic)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package bitfield
+
+// SplitInto copies consecutive ranges of bits of src into a given list of parts, in order e.g. a
+// Bitvector512 into four Bitvector128 sync subcommittees. Parts may be of any type and size, and
+// previous contents of the parts are overwritten.
+// This method will return an error if lengths of the parts do not add up to the length of src.
+func SplitInto(src Bitfield, parts ...Bitfield) error {
+	var total uint64
+	for _, part := range parts {
+		total += part.Len()
+		if total < part.Len() {
+			return ErrWrongLen
+		}
+	}
+	if total != src.Len() {
+		return ErrWrongLen
+	}
+
+	var offset uint64
+	for _, part := range parts {
+		if err := ExtractAt(part, src, offset); err != nil {
+			return err
+		}
+		offset += part.Len()
+	}
+	return nil
+}
+
+// ExtractAt copies dst.Len() bits of src, starting at the given bit offset, into dst. Previous
+// contents of dst are overwritten.
+// This method will return an error if the range does not fit into src.
+func ExtractAt(dst, src Bitfield, offset uint64) error {
+	n := dst.Len()
+	if !rangeFits(offset, n, src.Len()) {
+		return ErrWrongLen
+	}
+
+	// Copy whole bytes at once when the offset is byte aligned.
+	var start uint64
+	if offset%8 == 0 {
+		d, s := rawBytes(dst), rawBytes(src)
+		if d != nil && s != nil {
+			start = n &^ 7
+			copy(d[:start>>3], s[offset>>3:])
+		}
+	}
+	for i := start; i < n; i++ {
+		dst.SetBitAt(i, src.BitAt(offset+i))
+	}
+	return nil
+}
+
+// MergeAt ORs all bits of part into dst, starting at the given bit offset e.g. a Bitvector128
+// sync subcommittee contribution into a Bitvector512 aggregate. Bits already set in dst are kept.
+// This method will return an error if the part does not fit into dst at the given offset.
+func MergeAt(dst, part Bitfield, offset uint64) error {
+	n := part.Len()
+	if !rangeFits(offset, n, dst.Len()) {
+		return ErrWrongLen
+	}
+
+	// Merge whole bytes at once when the offset is byte aligned.
+	var start uint64
+	if offset%8 == 0 {
+		d, p := rawBytes(dst), rawBytes(part)
+		if d != nil && p != nil {
+			start = n &^ 7
+			d = d[offset>>3:]
+			for i := uint64(0); i < start>>3; i++ {
+				d[i] |= p[i]
+			}
+		}
+	}
+	for i := start; i < n; i++ {
+		if part.BitAt(i) {
+			dst.SetBitAt(offset+i, true)
+		}
+	}
+	return nil
+}
+
+// MergeAll ORs every part into dst at the corresponding offset i.e. parts[i] is merged at
+// offsets[i]. It allows aggregating all contributions at once, and several parts may share the
+// same offset. If any part does not fit, dst is left untouched.
+// This method will return an error if the number of parts and offsets differ, or if any of the
+// parts does not fit into dst.
+func MergeAll(dst Bitfield, parts []Bitfield, offsets []uint64) error {
+	if len(parts) != len(offsets) {
+		return ErrWrongLen
+	}
+	for i, part := range parts {
+		if !rangeFits(offsets[i], part.Len(), dst.Len()) {
+			return ErrWrongLen
+		}
+	}
+	for i, part := range parts {
+		if err := MergeAt(dst, part, offsets[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rangeFits returns true if n bits starting at offset fit into a bitfield of a given size.
+func rangeFits(offset, n, size uint64) bool {
+	return offset <= size && n <= size-offset
+}
+
+// rawBytes returns the byte array backing a given bitfield, if bit i of the bitfield is stored
+// at bit i%8 of byte i/8 and the array is well formed. Otherwise, nil is returned.
+func rawBytes(b Bitfield) []byte {
+	var raw []byte
+	var byteSize int
+	switch v := b.(type) {
+	case Bitlist:
+		return v
+	case Bitvector8:
+		raw, byteSize = v, bitvector8ByteSize
+	case Bitvector16:
+		raw, byteSize = v, bitvector16ByteSize
+	case Bitvector32:
+		raw, byteSize = v, bitvector32ByteSize
+	case Bitvector64:
+		raw, byteSize = v, bitvector64ByteSize
+	case Bitvector128:
+		raw, byteSize = v, bitvector128ByteSize
+	case Bitvector256:
+		raw, byteSize = v, bitvector256ByteSize
+	case Bitvector512:
+		raw, byteSize = v, bitvector512ByteSize
+	default:
+		return nil
+	}
+	if len(raw) != byteSize {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,206 @@
+package bitfield
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSplitInto(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	t.Run("Bitvector512 into Bitvector128", func(t *testing.T) {
+		src := NewBitvector512()
+		randomBits(r, src, 50)
+		parts := []Bitvector128{NewBitvector128(), NewBitvector128(), NewBitvector128(), NewBitvector128()}
+		if err := SplitInto(src, parts[0], parts[1], parts[2], parts[3]); err != nil {
+			t.Fatal(err)
+		}
+		for i, part := range parts {
+			for j := uint64(0); j < part.Len(); j++ {
+				if part.BitAt(j) != src.BitAt(uint64(i)*128+j) {
+					t.Fatalf("parts[%d].BitAt(%d) = %t, wanted %t", i, j, part.BitAt(j), src.BitAt(uint64(i)*128+j))
+				}
+			}
+		}
+	})
+
+	t.Run("mixed types and unaligned parts", func(t *testing.T) {
+		src := NewBitvector256()
+		randomBits(r, src, 50)
+		parts := []Bitfield{NewBitvector4(), NewBitlist(61), NewBitvector64(), NewBitlist64(127)}
+		// Make sure previous contents are overwritten.
+		for _, part := range parts {
+			for i := uint64(0); i < part.Len(); i++ {
+				part.SetBitAt(i, true)
+			}
+		}
+		if err := SplitInto(src, parts...); err != nil {
+			t.Fatal(err)
+		}
+		var offset uint64
+		for i, part := range parts {
+			for j := uint64(0); j < part.Len(); j++ {
+				if part.BitAt(j) != src.BitAt(offset+j) {
+					t.Fatalf("parts[%d].BitAt(%d) = %t, wanted %t", i, j, part.BitAt(j), src.BitAt(offset+j))
+				}
+			}
+			offset += part.Len()
+		}
+		if parts[1].Len() != 61 {
+			t.Errorf("Length of the bitlist part changed to %d", parts[1].Len())
+		}
+	})
+
+	t.Run("check errors", func(t *testing.T) {
+		src := NewBitvector512()
+		if err := SplitInto(src, NewBitvector128(), NewBitvector128()); err != ErrWrongLen {
+			t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+		}
+		if err := SplitInto(src, NewBitvector256(), NewBitvector256(), NewBitvector8()); err != ErrWrongLen {
+			t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+		}
+	})
+}
+
+func TestExtractAt(t *testing.T) {
+	src := NewBitvector128()
+	for _, i := range []uint64{0, 9, 10, 70, 127} {
+		src.SetBitAt(i, true)
+	}
+
+	dst := NewBitvector8()
+	if err := ExtractAt(dst, src, 3); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Bitvector8{0xC0}); !dst.Equal(want) {
+		t.Errorf("ExtractAt(_, _, 3) = %x, wanted %x", dst, want)
+	}
+	if err := ExtractAt(dst, src, 120); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Bitvector8{0x80}); !dst.Equal(want) {
+		t.Errorf("ExtractAt(_, _, 120) = %x, wanted %x", dst, want)
+	}
+	if err := ExtractAt(dst, src, 121); err != ErrWrongLen {
+		t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+	}
+	if err := ExtractAt(dst, src, ^uint64(0)); err != ErrWrongLen {
+		t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+	}
+}
+
+func TestMergeAt(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	t.Run("round trip", func(t *testing.T) {
+		tests := []struct {
+			dst   func() Bitfield
+			parts func() []Bitfield
+		}{
+			{
+				dst: func() Bitfield { return NewBitvector512() },
+				parts: func() []Bitfield {
+					return []Bitfield{NewBitvector128(), NewBitvector128(), NewBitvector128(), NewBitvector128()}
+				},
+			},
+			{
+				dst: func() Bitfield { return NewBitvector512() },
+				parts: func() []Bitfield {
+					return []Bitfield{
+						NewBitvector64(), NewBitvector64(), NewBitvector64(), NewBitvector64(),
+						NewBitvector64(), NewBitvector64(), NewBitvector64(), NewBitvector64(),
+					}
+				},
+			},
+			{
+				dst: func() Bitfield { return NewBitvector256() },
+				parts: func() []Bitfield {
+					return []Bitfield{NewBitvector64(), NewBitvector64(), NewBitvector64(), NewBitvector64()}
+				},
+			},
+			{
+				dst: func() Bitfield { return NewBitvector64() },
+				parts: func() []Bitfield {
+					return []Bitfield{NewBitvector4(), NewBitvector16(), NewBitlist(13), NewBitvector8(), NewBitlist64(23)}
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			src, parts := tt.dst(), tt.parts()
+			randomBits(r, src, 50)
+			if err := SplitInto(src, parts...); err != nil {
+				t.Fatal(err)
+			}
+			dst := tt.dst()
+			var offset uint64
+			for _, part := range parts {
+				if err := MergeAt(dst, part, offset); err != nil {
+					t.Fatal(err)
+				}
+				offset += part.Len()
+			}
+			if !Equal(dst, src) {
+				t.Errorf("Merged parts = %x, wanted %x", dst.Bytes(), src.Bytes())
+			}
+		}
+	})
+
+	t.Run("bits are OR-ed", func(t *testing.T) {
+		dst := NewBitvector16()
+		dst.SetBitAt(0, true)
+		dst.SetBitAt(9, true)
+		part := Bitvector8{0x81}
+		if err := MergeAt(dst, part, 5); err != nil {
+			t.Fatal(err)
+		}
+		if want := (Bitvector16{0x21, 0x12}); !dst.Equal(want) {
+			t.Errorf("MergeAt() = %x, wanted %x", dst, want)
+		}
+	})
+
+	t.Run("check errors", func(t *testing.T) {
+		if err := MergeAt(NewBitvector512(), NewBitvector128(), 385); err != ErrWrongLen {
+			t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+		}
+		if err := MergeAt(NewBitvector64(), NewBitvector128(), 0); err != ErrWrongLen {
+			t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+		}
+	})
+}
+
+func TestMergeAll(t *testing.T) {
+	contributions := []Bitfield{NewBitvector128(), NewBitvector128(), NewBitvector128()}
+	for i, c := range contributions {
+		c.SetBitAt(uint64(i), true)
+		c.SetBitAt(127, true)
+	}
+	dst := NewBitvector512()
+	// Two contributions for subcommittee 3, one for subcommittee 0.
+	if err := MergeAll(dst, contributions, []uint64{384, 0, 384}); err != nil {
+		t.Fatal(err)
+	}
+	want := []int{1, 127, 384, 386, 511}
+	got := dst.BitIndices()
+	if len(got) != len(want) {
+		t.Fatalf("MergeAll() = %v, wanted %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("MergeAll() = %v, wanted %v", got, want)
+		}
+	}
+
+	t.Run("check errors", func(t *testing.T) {
+		dst := NewBitvector512()
+		if err := MergeAll(dst, contributions, []uint64{0}); err != ErrWrongLen {
+			t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+		}
+		if err := MergeAll(dst, contributions, []uint64{0, 128, 512}); err != ErrWrongLen {
+			t.Errorf("Wrong error returned. Wanted %v, got %v", ErrWrongLen, err)
+		}
+		if dst.Count() != 0 {
+			t.Error("Failed MergeAll() modified the destination")
+		}
+	})
+}
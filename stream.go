@@ -0,0 +1,255 @@
+package bitfield
+
+import (
+	"encoding/binary"
+	"io"
+	"math/bits"
+)
+
+// streamWindow is the default number of words buffered by WriteTo and ReadFrom of bitlists.
+const streamWindow = 512
+
+// WordReader reads a bitlist encoded in the SSZ byte layout (as written by WriteTo) from a stream,
+// returning its bits as batches of words. It lets bitlists too large to be held in memory be
+// processed incrementally e.g. counted or merged word by word.
+//
+// The length bit is in the last byte of the stream, so the reader always holds back a single word
+// of lookahead, in addition to a window of words returned by Next().
+type WordReader struct {
+	r      io.Reader
+	window int
+	buf    []byte   // Bytes read, but not returned yet.
+	words  []uint64 // Batch returned by Next(), reused between calls.
+	read   int64    // Number of bytes read from the stream.
+	err    error    // Error returned by the stream, if any.
+
+	eof       bool   // Whether the whole stream was read, and the length bit found.
+	size      uint64 // Number of bits in the bitlist, known at the end of the stream.
+	remaining int    // Number of words left to be returned, known at the end of the stream.
+}
+
+// NewWordReader creates a reader returning up to `window` words at a time. The reader never
+// buffers more than window+1 words. A window smaller than 1 is treated as 1.
+func NewWordReader(r io.Reader, window int) *WordReader {
+	if window < 1 {
+		window = 1
+	}
+	return &WordReader{
+		r:      r,
+		window: window,
+		buf:    make([]byte, 0, (window+1)*bytesInWord),
+		words:  make([]uint64, window),
+	}
+}
+
+// Next returns the next batch of up to `window` words of the bitlist, with bits at or above its
+// length cleared. The batch is only valid until the next call. Once all words were returned, it
+// returns io.EOF, and Len() returns the length of the bitlist.
+// This method will return an error if the stream fails, or it does not end with a length bit.
+func (w *WordReader) Next() ([]uint64, error) {
+	if !w.eof {
+		for len(w.buf) < cap(w.buf) && w.err == nil {
+			n, err := w.r.Read(w.buf[len(w.buf):cap(w.buf)])
+			w.buf = w.buf[:len(w.buf)+n]
+			w.read += int64(n)
+			w.err = err
+		}
+		if w.err != nil && w.err != io.EOF {
+			return nil, w.err
+		}
+		if w.err == nil {
+			// More bytes may follow, so hold back the last byte, as it may hold the length bit.
+			// The buffer is full, so exactly `window` words are complete before it.
+			numWords := (len(w.buf) - 1) >> bytesInWordLog2
+			return w.consume(numWords), nil
+		}
+		if err := w.finish(); err != nil {
+			return nil, err
+		}
+	}
+
+	if w.remaining == 0 {
+		return nil, io.EOF
+	}
+	numWords := w.remaining
+	if numWords > w.window {
+		numWords = w.window
+	}
+	w.remaining -= numWords
+	return w.consume(numWords), nil
+}
+
+// Len returns the number of bits in the bitlist. It is only known once Next() returned io.EOF.
+func (w *WordReader) Len() uint64 {
+	return w.size
+}
+
+// BytesRead returns the number of bytes read from the stream so far.
+func (w *WordReader) BytesRead() int64 {
+	return w.read
+}
+
+// finish locates the length bit in the last byte of the stream, and clears it.
+func (w *WordReader) finish() error {
+	if len(w.buf) == 0 || w.buf[len(w.buf)-1] == 0 {
+		return ErrMissingLengthBit
+	}
+	last := len(w.buf) - 1
+	msb := bits.Len8(w.buf[last]) - 1
+	w.buf[last] &^= 1 << uint(msb)
+	w.size = uint64(w.read-1)<<3 + uint64(msb)
+	// All words before the buffer were returned already.
+	w.remaining = numWordsRequired(w.size) - int(uint64(w.read-int64(len(w.buf)))>>bytesInWordLog2)
+	w.eof = true
+	return nil
+}
+
+// consume packs the given number of words from the front of the buffer into a batch, removing
+// their bytes from the buffer. Missing bytes are zero.
+func (w *WordReader) consume(numWords int) []uint64 {
+	for i := 0; i < numWords; i++ {
+		var word [bytesInWord]byte
+		copy(word[:], w.buf[min(i<<bytesInWordLog2, len(w.buf)):])
+		w.words[i] = binary.LittleEndian.Uint64(word[:])
+	}
+	n := copy(w.buf, w.buf[min(numWords<<bytesInWordLog2, len(w.buf)):])
+	w.buf = w.buf[:n]
+	return w.words[:numWords]
+}
+
+// writeWords writes the first n bits of a word array followed by the length bit, in the SSZ byte
+// layout of a bitlist, buffering at most `window` words at a time.
+func writeWords(wr io.Writer, data []uint64, n uint64, window int) (int64, error) {
+	if window < 1 {
+		window = 1
+	}
+	numBytes := int(n>>3) + 1
+	buf := make([]byte, 0, window*bytesInWord)
+	var written int64
+	for i := 0; i<<bytesInWordLog2 < numBytes; i++ {
+		var word uint64
+		if i < len(data) {
+			word = data[i]
+		}
+		if i == int(n>>wordSizeLog2) {
+			// Clear bits above the length, and set the length bit.
+			word = word&(1<<(n%wordSize)-1) | 1<<(n%wordSize)
+		}
+		var tmp [bytesInWord]byte
+		binary.LittleEndian.PutUint64(tmp[:], word)
+		buf = append(buf, tmp[:min(bytesInWord, numBytes-i<<bytesInWordLog2)]...)
+
+		if len(buf) == cap(buf) || (i+1)<<bytesInWordLog2 >= numBytes {
+			m, err := wr.Write(buf)
+			written += int64(m)
+			if err != nil {
+				return written, err
+			}
+			buf = buf[:0]
+		}
+	}
+	return written, nil
+}
+
+// WriteTo writes the bitlist to a given writer in the SSZ byte layout, including the length bit.
+// The bitlist is already in that layout, so it is written without copying.
+// This method will return an error if the bitlist does not end with a length bit, as it could not
+// be read back, or if the writer fails.
+func (b Bitlist) WriteTo(w io.Writer) (int64, error) {
+	if len(b) == 0 || b[len(b)-1] == 0 {
+		return 0, ErrMissingLengthBit
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadFrom reads a bitlist in the SSZ byte layout from a given reader, until the end of the
+// stream, replacing the bitlist.
+// This method will return an error if the stream fails, or it does not end with a length bit.
+func (b *Bitlist) ReadFrom(r io.Reader) (int64, error) {
+	return b.ReadFromWindow(r, streamWindow)
+}
+
+// ReadFromWindow is like ReadFrom, reading at most `window` words of the stream at a time. A
+// window smaller than 1 is treated as 1.
+//
+// When the reader reports the number of bytes left, as bytes.Reader and bytes.Buffer do, the
+// bitlist is allocated once with its final size. Otherwise it is built up incrementally, and may
+// briefly hold up to twice its final size while growing.
+// This method will return an error if the stream fails, or it does not end with a length bit.
+func (b *Bitlist) ReadFromWindow(r io.Reader, window int) (int64, error) {
+	if window < 1 {
+		window = 1
+	}
+	var buf []byte
+	if l, ok := r.(interface{ Len() int }); ok {
+		buf = make([]byte, 0, l.Len())
+	}
+	chunk := make([]byte, window*bytesInWord)
+	for {
+		n, err := r.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return int64(len(buf)), err
+		}
+	}
+	if len(buf) == 0 || buf[len(buf)-1] == 0 {
+		return int64(len(buf)), ErrMissingLengthBit
+	}
+	*b = buf
+	return int64(len(buf)), nil
+}
+
+// WriteTo writes the bitlist to a given writer in the SSZ byte layout, including the length bit,
+// converting words to bytes a window at a time rather than copying the whole bitlist.
+func (b *Bitlist64) WriteTo(w io.Writer) (int64, error) {
+	return b.WriteToWindow(w, streamWindow)
+}
+
+// WriteToWindow is like WriteTo, buffering at most `window` words at a time. A window smaller
+// than 1 is treated as 1.
+func (b *Bitlist64) WriteToWindow(w io.Writer, window int) (int64, error) {
+	return writeWords(w, b.data, b.size, window)
+}
+
+// ReadFrom reads a bitlist in the SSZ byte layout from a given reader, until the end of the
+// stream, replacing the bitlist. Bytes are packed into words a window at a time, so the stream is
+// never held in memory twice.
+// This method will return an error if the stream fails, or it does not end with a length bit.
+func (b *Bitlist64) ReadFrom(r io.Reader) (int64, error) {
+	return b.ReadFromWindow(r, streamWindow)
+}
+
+// ReadFromWindow is like ReadFrom, buffering at most `window` words (plus a word of lookahead) of
+// the stream at a time. A window smaller than 1 is treated as 1.
+//
+// When the reader reports the number of bytes left, as bytes.Reader and bytes.Buffer do, the
+// bitlist is allocated once with its final size. Otherwise it is built up incrementally, and may
+// briefly hold up to twice its final size while growing.
+// This method will return an error if the stream fails, or it does not end with a length bit.
+func (b *Bitlist64) ReadFromWindow(r io.Reader, window int) (int64, error) {
+	var data []uint64
+	if l, ok := r.(interface{ Len() int }); ok {
+		data = make([]uint64, 0, (l.Len()+bytesInWord-1)>>bytesInWordLog2)
+	}
+	wr := NewWordReader(r, window)
+	for {
+		words, err := wr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return wr.BytesRead(), err
+		}
+		data = append(data, words...)
+	}
+	b.size = wr.Len()
+	b.data = data
+	if b.data == nil {
+		b.data = make([]uint64, 0)
+	}
+	return wr.BytesRead(), nil
+}
@@ -0,0 +1,296 @@
+package bitfield
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestBitlist_WriteTo(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 7, 8, 9, 63, 64, 65, 1000, 64 * streamWindow, 64*streamWindow + 9, 100000} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			want := NewBitlist(n)
+			randomBits(r, want, 50)
+
+			var buf bytes.Buffer
+			written, err := want.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written != int64(len(want)) || !bytes.Equal(buf.Bytes(), want) {
+				t.Fatalf("WriteTo() = %d, %#x, wanted %d, %#x", written, buf.Bytes(), len(want), want)
+			}
+
+			b64, err := want.ToBitlist64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf64 bytes.Buffer
+			written, err = b64.WriteTo(&buf64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written != int64(len(want)) || !bytes.Equal(buf64.Bytes(), want) {
+				t.Fatalf("Bitlist64.WriteTo() = %d, %#x, wanted %d, %#x", written, buf64.Bytes(), len(want), want)
+			}
+
+			var got Bitlist
+			read, err := got.ReadFrom(iotest.HalfReader(bytes.NewReader(want)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read != int64(len(want)) || !got.Equal(want) {
+				t.Errorf("ReadFrom() = %d, %#x, wanted %d, %#x", read, got, len(want), want)
+			}
+
+			got64 := NewBitlist64(1)
+			read, err = got64.ReadFrom(iotest.OneByteReader(bytes.NewReader(want)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read != int64(len(want)) || !got64.Equal(b64) {
+				t.Errorf("Bitlist64.ReadFrom() = %d, %v, wanted %d, %v", read, got64, len(want), b64)
+			}
+		})
+	}
+
+	t.Run("window", func(t *testing.T) {
+		want := NewBitlist64(64*10 + 9)
+		randomBits(r, want, 50)
+		for _, window := range []int{0, 1, 2, 3, 10, 11, 100} {
+			w := &recordingWriter{}
+			if _, err := want.WriteToWindow(w, window); err != nil {
+				t.Fatal(err)
+			}
+			max := 8 * window
+			if window < 1 {
+				max = 8
+			}
+			if w.maxWrite > max {
+				t.Errorf("WriteToWindow(%d) wrote %d bytes at once, wanted at most %d", window, w.maxWrite, max)
+			}
+
+			got := NewBitlist64(1)
+			if _, err := got.ReadFromWindow(iotest.HalfReader(bytes.NewReader(w.Bytes())), window); err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("ReadFromWindow(%d) = %v, wanted %v", window, got, want)
+			}
+
+			var gotBitlist Bitlist
+			rr := &recordingReader{r: iotest.OneByteReader(bytes.NewReader(w.Bytes()))}
+			read, err := gotBitlist.ReadFromWindow(rr, window)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read != int64(w.Len()) || !bytes.Equal(gotBitlist, w.Bytes()) {
+				t.Errorf("Bitlist.ReadFromWindow(%d) = %d, %#x, wanted %d, %#x", window, read, gotBitlist, w.Len(), w.Bytes())
+			}
+			if rr.maxRead > max {
+				t.Errorf("Bitlist.ReadFromWindow(%d) read %d bytes at once, wanted at most %d", window, rr.maxRead, max)
+			}
+		}
+	})
+
+	t.Run("pre-sized", func(t *testing.T) {
+		want := NewBitlist64(64*streamWindow*3 + 9)
+		randomBits(r, want, 50)
+		var buf bytes.Buffer
+		if _, err := want.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		encoded := append([]byte(nil), buf.Bytes()...)
+		got := NewBitlist64(1)
+		if _, err := got.ReadFrom(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(want) || cap(got.data) != len(want.data) {
+			t.Errorf("ReadFrom() = %d words of capacity %d, wanted %d", len(got.data), cap(got.data), len(want.data))
+		}
+
+		var gotBitlist Bitlist
+		if _, err := gotBitlist.ReadFrom(bytes.NewReader(encoded)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(gotBitlist, encoded) || cap(gotBitlist) != len(encoded) {
+			t.Errorf("Bitlist.ReadFrom() = %d bytes of capacity %d, wanted %d", len(gotBitlist), cap(gotBitlist), len(encoded))
+		}
+	})
+
+	t.Run("bits above size", func(t *testing.T) {
+		var buf bytes.Buffer
+		if _, err := (&Bitlist64{size: 4, data: []uint64{0xFF}}).WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if want := []byte{0x1F}; !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("WriteTo() = %#x, wanted %#x", buf.Bytes(), want)
+		}
+	})
+}
+
+func TestWordReader(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 63, 64, 65, 127, 128, 129, 191, 192, 1000} {
+		for _, window := range []int{0, 1, 2, 3, 16} {
+			t.Run(fmt.Sprintf("size:%d,window:%d", n, window), func(t *testing.T) {
+				want := NewBitlist64(n)
+				randomBits(r, want, 50)
+				var buf bytes.Buffer
+				if _, err := want.WriteTo(&buf); err != nil {
+					t.Fatal(err)
+				}
+				encoded := buf.Len()
+
+				wr := NewWordReader(iotest.HalfReader(&buf), window)
+				maxBatch := window
+				if maxBatch < 1 {
+					maxBatch = 1
+				}
+				var data []uint64
+				for {
+					words, err := wr.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(words) == 0 || len(words) > maxBatch {
+						t.Fatalf("Next() returned %d words, wanted 1 to %d", len(words), maxBatch)
+					}
+					if cap(wr.buf) > (maxBatch+1)*bytesInWord {
+						t.Fatalf("buffered %d bytes, wanted at most %d", cap(wr.buf), (maxBatch+1)*bytesInWord)
+					}
+					data = append(data, words...)
+				}
+				if wr.Len() != n {
+					t.Errorf("Len() = %d, wanted %d", wr.Len(), n)
+				}
+				if wr.BytesRead() != int64(encoded) {
+					t.Errorf("BytesRead() = %d, wanted %d", wr.BytesRead(), encoded)
+				}
+				if data == nil {
+					data = []uint64{}
+				}
+				if !reflect.DeepEqual(data, want.data) {
+					t.Errorf("Next() = %#x, wanted %#x", data, want.data)
+				}
+				if _, err := wr.Next(); err != io.EOF {
+					t.Errorf("Next() after the end error = %v, wanted %v", err, io.EOF)
+				}
+			})
+		}
+	}
+}
+
+func TestWordReader_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "zero last byte", data: []byte{0x01, 0x00}},
+		{name: "zero last byte after a window", data: make([]byte, 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := NewWordReader(bytes.NewReader(tt.data), 2)
+			var err error
+			for err == nil {
+				_, err = wr.Next()
+			}
+			if err != ErrMissingLengthBit {
+				t.Errorf("Next() error = %v, wanted %v", err, ErrMissingLengthBit)
+			}
+
+			var buf bytes.Buffer
+			if n, err := Bitlist(tt.data).WriteTo(&buf); err != ErrMissingLengthBit || n != 0 || buf.Len() != 0 {
+				t.Errorf("WriteTo() = %d, %v, wanted 0, %v", n, err, ErrMissingLengthBit)
+			}
+
+			var b Bitlist
+			if _, err := b.ReadFrom(bytes.NewReader(tt.data)); err != ErrMissingLengthBit {
+				t.Errorf("ReadFrom() error = %v, wanted %v", err, ErrMissingLengthBit)
+			}
+			if _, err := NewBitlist64(1).ReadFrom(bytes.NewReader(tt.data)); err != ErrMissingLengthBit {
+				t.Errorf("Bitlist64.ReadFrom() error = %v, wanted %v", err, ErrMissingLengthBit)
+			}
+		})
+	}
+
+	t.Run("stream error", func(t *testing.T) {
+		data := bytes.Repeat([]byte{0xFF}, 100)
+		wr := NewWordReader(iotest.DataErrReader(iotest.TimeoutReader(bytes.NewReader(data))), 2)
+		var err error
+		for err == nil {
+			_, err = wr.Next()
+		}
+		if err != iotest.ErrTimeout {
+			t.Errorf("Next() error = %v, wanted %v", err, iotest.ErrTimeout)
+		}
+		if _, err := NewBitlist64(1).ReadFrom(iotest.TimeoutReader(bytes.NewReader(data))); err != iotest.ErrTimeout {
+			t.Errorf("Bitlist64.ReadFrom() error = %v, wanted %v", err, iotest.ErrTimeout)
+		}
+		var b Bitlist
+		if _, err := b.ReadFrom(iotest.TimeoutReader(bytes.NewReader(data))); err != iotest.ErrTimeout {
+			t.Errorf("ReadFrom() error = %v, wanted %v", err, iotest.ErrTimeout)
+		}
+	})
+}
+
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, io.ErrShortWrite
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+// recordingWriter records the size of the largest write.
+type recordingWriter struct {
+	bytes.Buffer
+	maxWrite int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.maxWrite {
+		w.maxWrite = len(p)
+	}
+	return w.Buffer.Write(p)
+}
+
+// recordingReader records the size of the largest read requested.
+type recordingReader struct {
+	r       io.Reader
+	maxRead int
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	if len(p) > r.maxRead {
+		r.maxRead = len(p)
+	}
+	return r.r.Read(p)
+}
+
+func TestBitlist64_WriteTo_Error(t *testing.T) {
+	b := NewBitlist64(64*streamWindow*3 + 5)
+	written, err := b.WriteTo(&failingWriter{limit: 8*streamWindow + 10})
+	if err != io.ErrShortWrite {
+		t.Errorf("WriteTo() error = %v, wanted %v", err, io.ErrShortWrite)
+	}
+	if written != 8*streamWindow+10 {
+		t.Errorf("WriteTo() = %d, wanted %d", written, 8*streamWindow+10)
+	}
+}
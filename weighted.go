@@ -0,0 +1,80 @@
+package bitfield
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// addWeights adds weights of all bits set in a given word to the sum. Word's least significant bit
+// corresponds to weights[offset]. This function will return an error if the sum overflows.
+func addWeights(sum, word uint64, offset int, weights []uint64) (uint64, error) {
+	var carry uint64
+	for word != 0 {
+		sum, carry = bits.Add64(sum, weights[offset+bits.TrailingZeros64(word)], 0)
+		if carry != 0 {
+			return 0, ErrWeightedSumOverflow
+		}
+		// Clear the rightmost set bit.
+		word &= word - 1
+	}
+	return sum, nil
+}
+
+// weightedSumBits sums weights of the bits set in the first n bits of a byte array. If mask is
+// non-nil, only bits also set in the mask are taken into account. Bytes are read as little-endian
+// words, so that they are walked like the words of a Bitlist64.
+func weightedSumBits(b, mask []byte, n uint64, weights []uint64) (uint64, error) {
+	return weightedSum(n, weights, func(i int) uint64 {
+		word := bytesWord(b, i, n)
+		if mask != nil {
+			word &= bytesWord(mask, i, n)
+		}
+		return word
+	})
+}
+
+// weightedSumWords sums weights of the bits set in the first n bits of a word array. If mask is
+// non-nil, only bits also set in the mask are taken into account.
+func weightedSumWords(data, mask []uint64, n uint64, weights []uint64) (uint64, error) {
+	return weightedSum(n, weights, func(i int) uint64 {
+		word := data[i]
+		if mask != nil {
+			word &= mask[i]
+		}
+		return word
+	})
+}
+
+// weightedSum sums weights of the bits set in the first n bits of a bitfield, given a function
+// returning its i-th word. Bits of the last word at or above the length are ignored.
+func weightedSum(n uint64, weights []uint64, word func(i int) uint64) (uint64, error) {
+	if uint64(len(weights)) < n {
+		return 0, ErrWeightsTooShort
+	}
+
+	var sum uint64
+	var err error
+	numWords := numWordsRequired(n)
+	for i := 0; i < numWords; i++ {
+		w := word(i)
+		if i == numWords-1 {
+			w &= lastWordMask(n)
+		}
+		if sum, err = addWeights(sum, w, i<<wordSizeLog2, weights); err != nil {
+			return 0, err
+		}
+	}
+	return sum, nil
+}
+
+// bytesWord returns the i-th little-endian word of the first n bits of a byte array. Only the last,
+// partial word is assembled byte by byte, bytes past the length are never read.
+func bytesWord(b []byte, i int, n uint64) uint64 {
+	start := i << bytesInWordLog2
+	if numBytes := int((n + 7) >> 3); start+bytesInWord > numBytes {
+		var buf [bytesInWord]byte
+		copy(buf[:], b[start:numBytes])
+		return binary.LittleEndian.Uint64(buf[:])
+	}
+	return binary.LittleEndian.Uint64(b[start:])
+}
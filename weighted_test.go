@@ -0,0 +1,118 @@
+package bitfield
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func naiveWeightedSum(b, mask Bitfield, weights []uint64) uint64 {
+	var sum uint64
+	for i := uint64(0); i < b.Len(); i++ {
+		if b.BitAt(i) && (mask == nil || mask.BitAt(i)) {
+			sum += weights[i]
+		}
+	}
+	return sum
+}
+
+func TestBitlist_WeightedSum(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 7, 8, 9, 63, 64, 65, 127, 128, 129, 200, 1024} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			weights := make([]uint64, n+3)
+			for i := range weights {
+				weights[i] = uint64(r.Int63n(1 << 40))
+			}
+			a, b := NewBitlist(n), NewBitlist(n)
+			randomBits(r, a, 50)
+			randomBits(r, b, 50)
+
+			got, err := a.WeightedSum(weights)
+			if want := naiveWeightedSum(a, nil, weights); got != want || err != nil {
+				t.Errorf("(%x).WeightedSum() = %d, %v, wanted %d", a, got, err, want)
+			}
+			got, err = a.WeightedSumAnd(b, weights)
+			if want := naiveWeightedSum(a, b, weights); got != want || err != nil {
+				t.Errorf("(%x).WeightedSumAnd(%x) = %d, %v, wanted %d", a, b, got, err, want)
+			}
+		})
+	}
+
+	t.Run("check errors", func(t *testing.T) {
+		a := NewBitlist(8)
+		a.SetBitAt(0, true)
+		a.SetBitAt(7, true)
+		if _, err := a.WeightedSum(make([]uint64, 7)); err != ErrWeightsTooShort {
+			t.Errorf("WeightedSum() error = %v, wanted %v", err, ErrWeightsTooShort)
+		}
+		if _, err := a.WeightedSumAnd(a, make([]uint64, 7)); err != ErrWeightsTooShort {
+			t.Errorf("WeightedSumAnd() error = %v, wanted %v", err, ErrWeightsTooShort)
+		}
+		if _, err := a.WeightedSumAnd(NewBitlist(9), make([]uint64, 9)); err != ErrBitlistDifferentLength {
+			t.Errorf("WeightedSumAnd() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		weights := []uint64{math.MaxUint64, 0, 0, 0, 0, 0, 0, 1}
+		if _, err := a.WeightedSum(weights); err != ErrWeightedSumOverflow {
+			t.Errorf("WeightedSum() error = %v, wanted %v", err, ErrWeightedSumOverflow)
+		}
+		if _, err := a.WeightedSumAnd(a, weights); err != ErrWeightedSumOverflow {
+			t.Errorf("WeightedSumAnd() error = %v, wanted %v", err, ErrWeightedSumOverflow)
+		}
+	})
+}
+
+func TestBitlist64_WeightedSum(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 7, 8, 9, 63, 64, 65, 127, 128, 129, 200, 1024} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			weights := make([]uint64, n)
+			for i := range weights {
+				weights[i] = uint64(r.Int63n(1 << 40))
+			}
+			a, b := NewBitlist64(n), NewBitlist64(n)
+			randomBits(r, a, 50)
+			randomBits(r, b, 50)
+
+			got, err := a.WeightedSum(weights)
+			if want := naiveWeightedSum(a, nil, weights); got != want || err != nil {
+				t.Errorf("(%+v).WeightedSum() = %d, %v, wanted %d", a, got, err, want)
+			}
+			got, err = a.WeightedSumAnd(b, weights)
+			if want := naiveWeightedSum(a, b, weights); got != want || err != nil {
+				t.Errorf("(%+v).WeightedSumAnd(%+v) = %d, %v, wanted %d", a, b, got, err, want)
+			}
+		})
+	}
+
+	t.Run("bits above size are ignored", func(t *testing.T) {
+		a := &Bitlist64{size: 4, data: []uint64{0xF1}}
+		if got, err := a.WeightedSum([]uint64{1, 2, 3, 4}); got != 1 || err != nil {
+			t.Errorf("WeightedSum() = %d, %v, wanted 1", got, err)
+		}
+	})
+
+	t.Run("check errors", func(t *testing.T) {
+		a := NewBitlist64(65)
+		a.SetBitAt(0, true)
+		a.SetBitAt(64, true)
+		if _, err := a.WeightedSum(make([]uint64, 64)); err != ErrWeightsTooShort {
+			t.Errorf("WeightedSum() error = %v, wanted %v", err, ErrWeightsTooShort)
+		}
+		if _, err := a.WeightedSumAnd(a, make([]uint64, 64)); err != ErrWeightsTooShort {
+			t.Errorf("WeightedSumAnd() error = %v, wanted %v", err, ErrWeightsTooShort)
+		}
+		if _, err := a.WeightedSumAnd(NewBitlist64(64), make([]uint64, 65)); err != ErrBitlistDifferentLength {
+			t.Errorf("WeightedSumAnd() error = %v, wanted %v", err, ErrBitlistDifferentLength)
+		}
+		weights := make([]uint64, 65)
+		weights[0], weights[64] = math.MaxUint64-1, 2
+		if _, err := a.WeightedSum(weights); err != ErrWeightedSumOverflow {
+			t.Errorf("WeightedSum() error = %v, wanted %v", err, ErrWeightedSumOverflow)
+		}
+		if _, err := a.WeightedSumAnd(a, weights); err != ErrWeightedSumOverflow {
+			t.Errorf("WeightedSumAnd() error = %v, wanted %v", err, ErrWeightedSumOverflow)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package bitfield
+
+import "math/bits"
+
+// Word kernels below are the portable implementations of bulk operations of Bitlist64. On amd64
+// and arm64, assembly versions are used instead when the CPU supports them, unless built with the
+// purego tag. They are also used for words left over by the assembly versions, which process
+// whole blocks only.
+
+// popcountWordsGeneric returns the number of 1s in a given array of words.
+func popcountWordsGeneric(a []uint64) uint64 {
+	var c int
+	for _, word := range a {
+		c += bits.OnesCount64(word)
+	}
+	return uint64(c)
+}
+
+// popcountOrWordsGeneric returns the number of 1s in the OR of two arrays of words, which must be
+// of the same length.
+func popcountOrWordsGeneric(a, b []uint64) uint64 {
+	b = b[:len(a)]
+	var c int
+	for idx, word := range a {
+		c += bits.OnesCount64(word | b[idx])
+	}
+	return uint64(c)
+}
+
+// popcountAndWordsGeneric returns the number of 1s in the AND of two arrays of words, which must be
+// of the same length.
+func popcountAndWordsGeneric(a, b []uint64) uint64 {
+	b = b[:len(a)]
+	var c int
+	for idx, word := range a {
+		c += bits.OnesCount64(word & b[idx])
+	}
+	return uint64(c)
+}
+
+// popcountXorWordsGeneric returns the number of 1s in the XOR of two arrays of words, which must be
+// of the same length.
+func popcountXorWordsGeneric(a, b []uint64) uint64 {
+	b = b[:len(a)]
+	var c int
+	for idx, word := range a {
+		c += bits.OnesCount64(word ^ b[idx])
+	}
+	return uint64(c)
+}
+
+// orWordsGeneric stores the OR of two arrays of words in dst. All arrays must be of the same length.
+func orWordsGeneric(dst, a, b []uint64) {
+	dst, b = dst[:len(a)], b[:len(a)]
+	for idx, word := range a {
+		dst[idx] = word | b[idx]
+	}
+}
+
+// andWordsGeneric stores the AND of two arrays of words in dst. All arrays must be of the same
+// length.
+func andWordsGeneric(dst, a, b []uint64) {
+	dst, b = dst[:len(a)], b[:len(a)]
+	for idx, word := range a {
+		dst[idx] = word & b[idx]
+	}
+}
+
+// xorWordsGeneric stores the XOR of two arrays of words in dst. All arrays must be of the same
+// length.
+func xorWordsGeneric(dst, a, b []uint64) {
+	dst, b = dst[:len(a)], b[:len(a)]
+	for idx, word := range a {
+		dst[idx] = word ^ b[idx]
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !purego
+// +build !purego
+
+package bitfield
+
+import "golang.org/x/sys/cpu"
+
+const (
+	// popcountBlockWords is the number of words counted by a single iteration of the Harley-Seal
+	// popcount i.e. 16 vectors of 256 bits.
+	popcountBlockWords = 64
+	// bulkBlockWords is the number of words processed by a single iteration of bulk operations
+	// i.e. 4 vectors of 256 bits.
+	bulkBlockWords = 16
+)
+
+// hasWordsAsm is true if the CPU supports AVX2, which all assembly kernels rely on.
+var hasWordsAsm = cpu.X86.HasAVX2
+
+//go:noescape
+func popcountWordsAsm(a []uint64) uint64
+
+//go:noescape
+func popcountOrWordsAsm(a, b []uint64) uint64
+
+//go:noescape
+func popcountAndWordsAsm(a, b []uint64) uint64
+
+//go:noescape
+func popcountXorWordsAsm(a, b []uint64) uint64
+
+//go:noescape
+func orWordsAsm(dst, a, b []uint64)
+
+//go:noescape
+func andWordsAsm(dst, a, b []uint64)
+
+//go:noescape
+func xorWordsAsm(dst, a, b []uint64)
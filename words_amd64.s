@@ -0,0 +1,222 @@
+//go:build !purego
+// +build !purego
+
+#include "textflag.h"
+
+// Popcounts of all nibbles, repeated for both 128-bit lanes.
+DATA popcntLUT<>+0x00(SB)/8, $0x0302020102010100
+DATA popcntLUT<>+0x08(SB)/8, $0x0403030203020201
+DATA popcntLUT<>+0x10(SB)/8, $0x0302020102010100
+DATA popcntLUT<>+0x18(SB)/8, $0x0403030203020201
+GLOBL popcntLUT<>(SB), RODATA|NOPTR, $32
+
+DATA nibbleMask<>+0x00(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA nibbleMask<>+0x08(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA nibbleMask<>+0x10(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA nibbleMask<>+0x18(SB)/8, $0x0f0f0f0f0f0f0f0f
+GLOBL nibbleMask<>(SB), RODATA|NOPTR, $32
+
+// The Harley-Seal popcount (Muła, Kurz and Lemire, "Faster Population Counts Using AVX2
+// Instructions") feeds 16 vectors at a time into a tree of carry-save adders, and counts bits of
+// the carries into the 16s only. The counters of 1s, 2s, 4s and 8s carry over to the next block,
+// and are counted once at the end.
+//
+// Registers:
+//   Y0-Y3   ones, twos, fours and eights
+//   Y4      total, as four 64-bit lanes
+//   Y5-Y11  twosA, twosB, foursA, foursB, eightsA, eightsB and sixteens
+//   Y12-Y14 loaded vectors and scratch
+//   Y15     nibble mask
+//   SI, DI  the first and second operand
+//   CX      number of blocks left
+
+// CSA is a carry-save adder, setting h and l to the carry and sum bits of l + a + b. It clobbers
+// a and u.
+#define CSA(h, l, a, b, u) \
+	VPXOR a, l, u; \
+	VPAND a, l, h; \
+	VPAND b, u, a; \
+	VPOR  a, h, h; \
+	VPXOR b, u, l
+
+// POPCNT adds popcounts of each 64-bit lane of v to acc, using a lookup of nibbles. It clobbers
+// Y12-Y14.
+#define POPCNT(v, acc) \
+	VMOVDQU popcntLUT<>(SB), Y13; \
+	VPAND   Y15, v, Y12; \
+	VPSRLW  $4, v, Y14; \
+	VPAND   Y15, Y14, Y14; \
+	VPSHUFB Y12, Y13, Y12; \
+	VPSHUFB Y14, Y13, Y14; \
+	VPADDB  Y12, Y14, Y12; \
+	VPXOR   Y14, Y14, Y14; \
+	VPSADBW Y14, Y12, Y12; \
+	VPADDQ  Y12, acc, acc
+
+#define HARLEY_SEAL_INIT \
+	VPXOR   Y0, Y0, Y0; \
+	VPXOR   Y1, Y1, Y1; \
+	VPXOR   Y2, Y2, Y2; \
+	VPXOR   Y3, Y3, Y3; \
+	VPXOR   Y4, Y4, Y4; \
+	VMOVDQU nibbleMask<>(SB), Y15
+
+// HARLEY_SEAL_BLOCK counts a block of 16 vectors, loaded by the LOAD macro defined by each
+// function.
+#define HARLEY_SEAL_BLOCK \
+	LOAD(0, Y12);   LOAD(32, Y13);  CSA(Y5, Y0, Y12, Y13, Y14); \
+	LOAD(64, Y12);  LOAD(96, Y13);  CSA(Y6, Y0, Y12, Y13, Y14); \
+	CSA(Y7, Y1, Y5, Y6, Y14); \
+	LOAD(128, Y12); LOAD(160, Y13); CSA(Y5, Y0, Y12, Y13, Y14); \
+	LOAD(192, Y12); LOAD(224, Y13); CSA(Y6, Y0, Y12, Y13, Y14); \
+	CSA(Y8, Y1, Y5, Y6, Y14); \
+	CSA(Y9, Y2, Y7, Y8, Y14); \
+	LOAD(256, Y12); LOAD(288, Y13); CSA(Y5, Y0, Y12, Y13, Y14); \
+	LOAD(320, Y12); LOAD(352, Y13); CSA(Y6, Y0, Y12, Y13, Y14); \
+	CSA(Y7, Y1, Y5, Y6, Y14); \
+	LOAD(384, Y12); LOAD(416, Y13); CSA(Y5, Y0, Y12, Y13, Y14); \
+	LOAD(448, Y12); LOAD(480, Y13); CSA(Y6, Y0, Y12, Y13, Y14); \
+	CSA(Y8, Y1, Y5, Y6, Y14); \
+	CSA(Y10, Y2, Y7, Y8, Y14); \
+	CSA(Y11, Y3, Y9, Y10, Y14); \
+	POPCNT(Y11, Y4)
+
+// HARLEY_SEAL_FINISH stores 16*total + 8*eights + 4*fours + 2*twos + ones, summed over all
+// lanes, in ret.
+#define HARLEY_SEAL_FINISH(ret) \
+	VPSLLQ       $1, Y4, Y4; \
+	POPCNT(Y3, Y4); \
+	VPSLLQ       $1, Y4, Y4; \
+	POPCNT(Y2, Y4); \
+	VPSLLQ       $1, Y4, Y4; \
+	POPCNT(Y1, Y4); \
+	VPSLLQ       $1, Y4, Y4; \
+	POPCNT(Y0, Y4); \
+	VEXTRACTI128 $1, Y4, X5; \
+	VPADDQ       X5, X4, X4; \
+	VPEXTRQ      $1, X4, ret; \
+	MOVQ         X4, BX; \
+	ADDQ         BX, ret; \
+	VZEROUPPER
+
+// BULK combines blocks of 4 vectors of SI and DI using op, storing results in DX.
+#define BULK(op) \
+	MOVQ    dst_base+0(FP), DX; \
+	MOVQ    a_base+24(FP), SI; \
+	MOVQ    a_len+32(FP), CX; \
+	MOVQ    b_base+48(FP), DI; \
+	SHRQ    $4, CX; \
+loop: \
+	VMOVDQU 0(SI), Y0; \
+	VMOVDQU 32(SI), Y1; \
+	VMOVDQU 64(SI), Y2; \
+	VMOVDQU 96(SI), Y3; \
+	op      0(DI), Y0, Y0; \
+	op      32(DI), Y1, Y1; \
+	op      64(DI), Y2, Y2; \
+	op      96(DI), Y3, Y3; \
+	VMOVDQU Y0, 0(DX); \
+	VMOVDQU Y1, 32(DX); \
+	VMOVDQU Y2, 64(DX); \
+	VMOVDQU Y3, 96(DX); \
+	ADDQ    $128, SI; \
+	ADDQ    $128, DI; \
+	ADDQ    $128, DX; \
+	DECQ    CX; \
+	JNZ     loop; \
+	VZEROUPPER; \
+	RET
+
+// func popcountWordsAsm(a []uint64) uint64
+#define LOAD(off, dst) VMOVDQU off(SI), dst
+TEXT ·popcountWordsAsm(SB), NOSPLIT, $0-32
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	SHRQ $6, CX
+	HARLEY_SEAL_INIT
+
+loop:
+	HARLEY_SEAL_BLOCK
+	ADDQ $512, SI
+	DECQ CX
+	JNZ  loop
+
+	HARLEY_SEAL_FINISH(AX)
+	MOVQ AX, ret+24(FP)
+	RET
+#undef LOAD
+
+// func popcountOrWordsAsm(a, b []uint64) uint64
+#define LOAD(off, dst) VMOVDQU off(SI), dst; VPOR off(DI), dst, dst
+TEXT ·popcountOrWordsAsm(SB), NOSPLIT, $0-56
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	SHRQ $6, CX
+	HARLEY_SEAL_INIT
+
+loop:
+	HARLEY_SEAL_BLOCK
+	ADDQ $512, SI
+	ADDQ $512, DI
+	DECQ CX
+	JNZ  loop
+
+	HARLEY_SEAL_FINISH(AX)
+	MOVQ AX, ret+48(FP)
+	RET
+#undef LOAD
+
+// func popcountAndWordsAsm(a, b []uint64) uint64
+#define LOAD(off, dst) VMOVDQU off(SI), dst; VPAND off(DI), dst, dst
+TEXT ·popcountAndWordsAsm(SB), NOSPLIT, $0-56
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	SHRQ $6, CX
+	HARLEY_SEAL_INIT
+
+loop:
+	HARLEY_SEAL_BLOCK
+	ADDQ $512, SI
+	ADDQ $512, DI
+	DECQ CX
+	JNZ  loop
+
+	HARLEY_SEAL_FINISH(AX)
+	MOVQ AX, ret+48(FP)
+	RET
+#undef LOAD
+
+// func popcountXorWordsAsm(a, b []uint64) uint64
+#define LOAD(off, dst) VMOVDQU off(SI), dst; VPXOR off(DI), dst, dst
+TEXT ·popcountXorWordsAsm(SB), NOSPLIT, $0-56
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	SHRQ $6, CX
+	HARLEY_SEAL_INIT
+
+loop:
+	HARLEY_SEAL_BLOCK
+	ADDQ $512, SI
+	ADDQ $512, DI
+	DECQ CX
+	JNZ  loop
+
+	HARLEY_SEAL_FINISH(AX)
+	MOVQ AX, ret+48(FP)
+	RET
+#undef LOAD
+
+// func orWordsAsm(dst, a, b []uint64)
+TEXT ·orWordsAsm(SB), NOSPLIT, $0-72
+	BULK(VPOR)
+
+// func andWordsAsm(dst, a, b []uint64)
+TEXT ·andWordsAsm(SB), NOSPLIT, $0-72
+	BULK(VPAND)
+
+// func xorWordsAsm(dst, a, b []uint64)
+TEXT ·xorWordsAsm(SB), NOSPLIT, $0-72
+	BULK(VPXOR)
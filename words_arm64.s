@@ -0,0 +1,105 @@
+//go:build !purego
+// +build !purego
+
+#include "textflag.h"
+
+// Popcounts count bytes of 4 vectors at a time with VCNT, and sum them into a scalar total.
+//
+// Registers:
+//   R0, R1  the first and second operand
+//   R2      number of blocks left
+//   R3      total
+//   V0-V3   loaded vectors
+//   V4-V7   vectors of the second operand
+
+// POPCOUNT_BLOCK adds the popcount of V0-V3 to R3.
+#define POPCOUNT_BLOCK \
+	VCNT    V0.B16, V0.B16; \
+	VCNT    V1.B16, V1.B16; \
+	VCNT    V2.B16, V2.B16; \
+	VCNT    V3.B16, V3.B16; \
+	VADD    V1.B16, V0.B16, V0.B16; \
+	VADD    V3.B16, V2.B16, V2.B16; \
+	VADD    V2.B16, V0.B16, V0.B16; \
+	VUADDLV V0.B16, V0; \
+	VMOV    V0.H[0], R4; \
+	ADD     R4, R3, R3
+
+// POPCOUNT_OP adds the popcount of blocks of R0 and R1 combined using op to R3.
+#define POPCOUNT_OP(op) \
+	MOVD   a_base+0(FP), R0; \
+	MOVD   a_len+8(FP), R2; \
+	MOVD   b_base+24(FP), R1; \
+	LSR    $3, R2, R2; \
+	MOVD   ZR, R3; \
+loop: \
+	VLD1.P 64(R0), [V0.B16, V1.B16, V2.B16, V3.B16]; \
+	VLD1.P 64(R1), [V4.B16, V5.B16, V6.B16, V7.B16]; \
+	op     V4.B16, V0.B16, V0.B16; \
+	op     V5.B16, V1.B16, V1.B16; \
+	op     V6.B16, V2.B16, V2.B16; \
+	op     V7.B16, V3.B16, V3.B16; \
+	POPCOUNT_BLOCK; \
+	SUBS   $1, R2, R2; \
+	BNE    loop; \
+	MOVD   R3, ret+48(FP); \
+	RET
+
+// BULK combines blocks of R1 and R2 using op, storing results in R0.
+#define BULK(op) \
+	MOVD   dst_base+0(FP), R0; \
+	MOVD   a_base+24(FP), R1; \
+	MOVD   a_len+32(FP), R3; \
+	MOVD   b_base+48(FP), R2; \
+	LSR    $3, R3, R3; \
+loop: \
+	VLD1.P 64(R1), [V0.B16, V1.B16, V2.B16, V3.B16]; \
+	VLD1.P 64(R2), [V4.B16, V5.B16, V6.B16, V7.B16]; \
+	op     V4.B16, V0.B16, V0.B16; \
+	op     V5.B16, V1.B16, V1.B16; \
+	op     V6.B16, V2.B16, V2.B16; \
+	op     V7.B16, V3.B16, V3.B16; \
+	VST1.P [V0.B16, V1.B16, V2.B16, V3.B16], 64(R0); \
+	SUBS   $1, R3, R3; \
+	BNE    loop; \
+	RET
+
+// func popcountWordsAsm(a []uint64) uint64
+TEXT ·popcountWordsAsm(SB), NOSPLIT, $0-32
+	MOVD a_base+0(FP), R0
+	MOVD a_len+8(FP), R2
+	LSR  $3, R2, R2
+	MOVD ZR, R3
+
+loop:
+	VLD1.P 64(R0), [V0.B16, V1.B16, V2.B16, V3.B16]
+	POPCOUNT_BLOCK
+	SUBS   $1, R2, R2
+	BNE    loop
+
+	MOVD R3, ret+24(FP)
+	RET
+
+// func popcountOrWordsAsm(a, b []uint64) uint64
+TEXT ·popcountOrWordsAsm(SB), NOSPLIT, $0-56
+	POPCOUNT_OP(VORR)
+
+// func popcountAndWordsAsm(a, b []uint64) uint64
+TEXT ·popcountAndWordsAsm(SB), NOSPLIT, $0-56
+	POPCOUNT_OP(VAND)
+
+// func popcountXorWordsAsm(a, b []uint64) uint64
+TEXT ·popcountXorWordsAsm(SB), NOSPLIT, $0-56
+	POPCOUNT_OP(VEOR)
+
+// func orWordsAsm(dst, a, b []uint64)
+TEXT ·orWordsAsm(SB), NOSPLIT, $0-72
+	BULK(VORR)
+
+// func andWordsAsm(dst, a, b []uint64)
+TEXT ·andWordsAsm(SB), NOSPLIT, $0-72
+	BULK(VAND)
+
+// func xorWordsAsm(dst, a, b []uint64)
+TEXT ·xorWordsAsm(SB), NOSPLIT, $0-72
+	BULK(VEOR)
@@ -0,0 +1,76 @@
+//go:build (amd64 || arm64) && !purego
+// +build amd64 arm64
+// +build !purego
+
+package bitfield
+
+// Assembly kernels process whole blocks of words only, see popcountBlockWords and bulkBlockWords,
+// leaving remaining words to the portable kernels.
+
+func popcountWords(a []uint64) uint64 {
+	if !hasWordsAsm || len(a) < popcountBlockWords {
+		return popcountWordsGeneric(a)
+	}
+	n := len(a) &^ (popcountBlockWords - 1)
+	return popcountWordsAsm(a[:n]) + popcountWordsGeneric(a[n:])
+}
+
+func popcountOrWords(a, b []uint64) uint64 {
+	if !hasWordsAsm || len(a) < popcountBlockWords {
+		return popcountOrWordsGeneric(a, b)
+	}
+	b = b[:len(a)]
+	n := len(a) &^ (popcountBlockWords - 1)
+	return popcountOrWordsAsm(a[:n], b[:n]) + popcountOrWordsGeneric(a[n:], b[n:])
+}
+
+func popcountAndWords(a, b []uint64) uint64 {
+	if !hasWordsAsm || len(a) < popcountBlockWords {
+		return popcountAndWordsGeneric(a, b)
+	}
+	b = b[:len(a)]
+	n := len(a) &^ (popcountBlockWords - 1)
+	return popcountAndWordsAsm(a[:n], b[:n]) + popcountAndWordsGeneric(a[n:], b[n:])
+}
+
+func popcountXorWords(a, b []uint64) uint64 {
+	if !hasWordsAsm || len(a) < popcountBlockWords {
+		return popcountXorWordsGeneric(a, b)
+	}
+	b = b[:len(a)]
+	n := len(a) &^ (popcountBlockWords - 1)
+	return popcountXorWordsAsm(a[:n], b[:n]) + popcountXorWordsGeneric(a[n:], b[n:])
+}
+
+func orWords(dst, a, b []uint64) {
+	if !hasWordsAsm || len(a) < bulkBlockWords {
+		orWordsGeneric(dst, a, b)
+		return
+	}
+	dst, b = dst[:len(a)], b[:len(a)]
+	n := len(a) &^ (bulkBlockWords - 1)
+	orWordsAsm(dst[:n], a[:n], b[:n])
+	orWordsGeneric(dst[n:], a[n:], b[n:])
+}
+
+func andWords(dst, a, b []uint64) {
+	if !hasWordsAsm || len(a) < bulkBlockWords {
+		andWordsGeneric(dst, a, b)
+		return
+	}
+	dst, b = dst[:len(a)], b[:len(a)]
+	n := len(a) &^ (bulkBlockWords - 1)
+	andWordsAsm(dst[:n], a[:n], b[:n])
+	andWordsGeneric(dst[n:], a[n:], b[n:])
+}
+
+func xorWords(dst, a, b []uint64) {
+	if !hasWordsAsm || len(a) < bulkBlockWords {
+		xorWordsGeneric(dst, a, b)
+		return
+	}
+	dst, b = dst[:len(a)], b[:len(a)]
+	n := len(a) &^ (bulkBlockWords - 1)
+	xorWordsAsm(dst[:n], a[:n], b[:n])
+	xorWordsGeneric(dst[n:], a[n:], b[n:])
+}
@@ -0,0 +1,22 @@
+//go:build (amd64 || arm64) && !purego
+// +build amd64 arm64
+// +build !purego
+
+package bitfield
+
+import (
+	"os"
+	"testing"
+)
+
+// TestWordsAsm makes sure that the assembly kernels are used, when the CI job testing them asks
+// for it by setting BITFIELD_REQUIRE_ASM. Otherwise a runner lacking the required CPU features
+// would silently test the portable kernels only.
+func TestWordsAsm(t *testing.T) {
+	if os.Getenv("BITFIELD_REQUIRE_ASM") == "" {
+		t.Skip("BITFIELD_REQUIRE_ASM is not set")
+	}
+	if !hasWordsAsm {
+		t.Fatal("assembly kernels are not supported by this CPU")
+	}
+}
@@ -0,0 +1,32 @@
+//go:build purego || (!amd64 && !arm64)
+// +build purego !amd64,!arm64
+
+package bitfield
+
+func popcountWords(a []uint64) uint64 {
+	return popcountWordsGeneric(a)
+}
+
+func popcountOrWords(a, b []uint64) uint64 {
+	return popcountOrWordsGeneric(a, b)
+}
+
+func popcountAndWords(a, b []uint64) uint64 {
+	return popcountAndWordsGeneric(a, b)
+}
+
+func popcountXorWords(a, b []uint64) uint64 {
+	return popcountXorWordsGeneric(a, b)
+}
+
+func orWords(dst, a, b []uint64) {
+	orWordsGeneric(dst, a, b)
+}
+
+func andWords(dst, a, b []uint64) {
+	andWordsGeneric(dst, a, b)
+}
+
+func xorWords(dst, a, b []uint64) {
+	xorWordsGeneric(dst, a, b)
+}
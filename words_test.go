@@ -0,0 +1,131 @@
+package bitfield
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// randomWords returns n random words, with a given percentage of bits set.
+func randomWords(r *rand.Rand, n, density int) []uint64 {
+	ret := make([]uint64, n)
+	for i := range ret {
+		switch density {
+		case 0:
+		case 100:
+			ret[i] = allBitsSet
+		default:
+			for j := uint(0); j < 64; j++ {
+				if r.Intn(100) < density {
+					ret[i] |= 1 << j
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// TestWords compares the word kernels used on this platform, which may be implemented in
+// assembly, against the portable ones.
+func TestWords(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	lengths := []int{0, 1, 7, 8, 9, 15, 16, 17, 63, 64, 65, 127, 128, 129, 200, 1000, 64*100 + 17}
+	for _, n := range lengths {
+		for _, density := range []int{0, 1, 50, 100} {
+			// Operands start one word into their arrays, so that they are not aligned to vectors.
+			a := randomWords(r, n+1, density)[1:]
+			b := randomWords(r, n+1, 50)[1:]
+			t.Run(fmt.Sprintf("length:%d,density:%d", n, density), func(t *testing.T) {
+				if got, want := popcountWords(a), popcountWordsGeneric(a); got != want {
+					t.Errorf("popcountWords() = %d, wanted %d", got, want)
+				}
+				if got, want := popcountOrWords(a, b), popcountOrWordsGeneric(a, b); got != want {
+					t.Errorf("popcountOrWords() = %d, wanted %d", got, want)
+				}
+				if got, want := popcountAndWords(a, b), popcountAndWordsGeneric(a, b); got != want {
+					t.Errorf("popcountAndWords() = %d, wanted %d", got, want)
+				}
+				if got, want := popcountXorWords(a, b), popcountXorWordsGeneric(a, b); got != want {
+					t.Errorf("popcountXorWords() = %d, wanted %d", got, want)
+				}
+
+				bulk := []struct {
+					name    string
+					f       func(dst, a, b []uint64)
+					generic func(dst, a, b []uint64)
+				}{
+					{name: "orWords", f: orWords, generic: orWordsGeneric},
+					{name: "andWords", f: andWords, generic: andWordsGeneric},
+					{name: "xorWords", f: xorWords, generic: xorWordsGeneric},
+				}
+				for _, op := range bulk {
+					want := make([]uint64, n)
+					op.generic(want, a, b)
+
+					// A guard word after dst must not be written.
+					dst := randomWords(r, n+2, 50)[1:]
+					guard := dst[n]
+					op.f(dst[:n], a, b)
+					if !reflect.DeepEqual(dst[:n], want) {
+						t.Errorf("%s() = %#x, wanted %#x", op.name, dst[:n], want)
+					}
+					if dst[n] != guard {
+						t.Errorf("%s() wrote past the end of dst", op.name)
+					}
+
+					// Dst may be one of the operands.
+					inPlace := append(make([]uint64, 0, n), a...)
+					op.f(inPlace, inPlace, b)
+					if !reflect.DeepEqual(inPlace, want) {
+						t.Errorf("%s() in place = %#x, wanted %#x", op.name, inPlace, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWords_Bitlist64(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for _, n := range []uint64{0, 1, 64 * 64, 64*64*3 + 5, 1 << 20} {
+		t.Run(fmt.Sprintf("size:%d", n), func(t *testing.T) {
+			b, c := NewBitlist64(n), NewBitlist64(n)
+			randomBits(r, b, 50)
+			randomBits(r, c, 30)
+
+			var want, wantOr, wantAnd, wantXor uint64
+			for i := uint64(0); i < n; i++ {
+				x, y := b.BitAt(i), c.BitAt(i)
+				want += boolToUint64(x)
+				wantOr += boolToUint64(x || y)
+				wantAnd += boolToUint64(x && y)
+				wantXor += boolToUint64(x != y)
+			}
+			if got := b.Count(); got != want {
+				t.Errorf("Count() = %d, wanted %d", got, want)
+			}
+			if got, _ := b.OrCount(c); got != wantOr {
+				t.Errorf("OrCount() = %d, wanted %d", got, wantOr)
+			}
+			if got, _ := b.AndCount(c); got != wantAnd {
+				t.Errorf("AndCount() = %d, wanted %d", got, wantAnd)
+			}
+			if got, _ := b.XorCount(c); got != wantXor {
+				t.Errorf("XorCount() = %d, wanted %d", got, wantXor)
+			}
+			for _, op := range []struct {
+				f    func(*Bitlist64) (*Bitlist64, error)
+				want uint64
+			}{{b.Or, wantOr}, {b.And, wantAnd}, {b.Xor, wantXor}} {
+				ret, err := op.f(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := ret.Count(); got != op.want {
+					t.Errorf("Count() of the result = %d, wanted %d", got, op.want)
+				}
+			}
+		})
+	}
+}